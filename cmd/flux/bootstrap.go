@@ -122,6 +122,10 @@ func bootstrapValidate() error {
 		return err
 	}
 
+	if err := utils.ValidateRegistry(bootstrapArgs.registry); err != nil {
+		return err
+	}
+
 	return nil
 }
 
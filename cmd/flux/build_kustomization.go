@@ -0,0 +1,517 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resid"
+	kustypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fluxcd/flux2/internal/utils"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+)
+
+var buildKsCmd = &cobra.Command{
+	Use:               "kustomization [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&kustomizev1.KustomizationList{}),
+	Aliases:           []string{"ks"},
+	Short:             "Build a Kustomization locally",
+	Long: `The build kustomization command renders the manifests of a Kustomization from a local directory
+and prints them to stdout as they would be applied by kustomize-controller.
+The target namespace, patches and images of the Kustomization are applied on top of the directory,
+followed by the post-build variable substitutions.
+The directory defaults to the path of the Kustomization, relative to the current working directory,
+and it must contain a kustomization.yaml file.
+With --kustomization-file, the Kustomization is read from a local file and the build doesn't need a cluster,
+otherwise it is read from the cluster of the kubeconfig.
+The ConfigMaps and Secrets referenced by postBuild.substituteFrom are only read from the files given with --substitute-from-file
+when the flag is given, and from the cluster otherwise.
+The postBuild.substitute values take precedence over the substituteFrom ones,
+and the variables without a value are reported as warnings together with the fields referencing them.
+The build never applies the manifests to the cluster, so it needs no --dry-run flag.`,
+	Example: `  # Build the Kustomization from a checkout of its source
+  flux build kustomization my-app
+
+  # Build the Kustomization from an uncommitted local directory
+  flux build kustomization my-app --path=./deploy/overlays/staging
+
+  # Build the Kustomization without access to the cluster
+  flux build kustomization my-app \
+    --kustomization-file=./clusters/staging/my-app.yaml \
+    --substitute-from-file=./clusters/staging/cluster-vars.yaml
+`,
+	RunE: buildKsCmdRun,
+}
+
+type buildKsFlags struct {
+	path                string
+	kustomizationFile   string
+	substituteFromFiles []string
+}
+
+var buildKsArgs buildKsFlags
+
+func init() {
+	buildKsCmd.Flags().StringVar(&buildKsArgs.path, "path", "",
+		"local directory to build the manifests from, overrides the path of the Kustomization")
+	buildKsCmd.Flags().StringVar(&buildKsArgs.kustomizationFile, "kustomization-file", "",
+		"local file containing the Kustomization, instead of reading it from the cluster")
+	buildKsCmd.Flags().StringArrayVar(&buildKsArgs.substituteFromFiles, "substitute-from-file", nil,
+		"local file containing the ConfigMaps and Secrets referenced by postBuild.substituteFrom, may be repeated")
+	buildCmd.AddCommand(buildKsCmd)
+}
+
+const (
+	// substituteAnnotation can be set to substituteDisabledValue on an
+	// object to skip the post-build substitutions.
+	substituteAnnotation    = "kustomize.toolkit.fluxcd.io/substitute"
+	substituteDisabledValue = "disabled"
+)
+
+var substituteVarName = regexp.MustCompile(`^[_[:alpha:]][_[:alpha:][:digit:]]*$`)
+
+func buildKsCmdRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("kustomization name is required")
+	}
+	name := args[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	var kubeClient client.Client
+	var kustomization kustomizev1.Kustomization
+	if buildKsArgs.kustomizationFile != "" {
+		k, err := readKustomizationFile(buildKsArgs.kustomizationFile, name)
+		if err != nil {
+			return err
+		}
+		kustomization = *k
+	} else {
+		var err error
+		kubeClient, err = utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+		if err != nil {
+			return fmt.Errorf("reading the Kustomization from the cluster failed, use --kustomization-file to build without a cluster: %w", err)
+		}
+
+		namespacedName := types.NamespacedName{
+			Namespace: rootArgs.namespace,
+			Name:      name,
+		}
+		if err := kubeClient.Get(ctx, namespacedName, &kustomization); err != nil {
+			return err
+		}
+	}
+
+	path := buildKsArgs.path
+	if path == "" {
+		path = kustomization.Spec.Path
+	}
+
+	// the substituteFrom objects are only read from the cluster when no
+	// local files are given
+	if len(buildKsArgs.substituteFromFiles) > 0 {
+		kubeClient = nil
+	}
+	vars, err := postBuildVars(ctx, kubeClient, kustomization, buildKsArgs.substituteFromFiles)
+	if err != nil {
+		return err
+	}
+
+	objects, err := buildKustomization(path, kustomization)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if kustomization.Spec.PostBuild != nil {
+			if object, err = substituteVariables(object, vars); err != nil {
+				return err
+			}
+		}
+		data, err := yaml.Marshal(object.Object)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("---\n%s", data)
+	}
+	return nil
+}
+
+// buildKustomization runs kustomize on the given directory, with the
+// target namespace, patches and images of the Kustomization applied
+// on top, and returns the resulting objects.
+func buildKustomization(path string, kustomization kustomizev1.Kustomization) ([]*unstructured.Unstructured, error) {
+	fs := filesys.MakeFsOnDisk()
+
+	exists := false
+	for _, kfilename := range konfig.RecognizedKustomizationFileNames() {
+		if fs.Exists(filepath.Join(path, kfilename)) {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		return nil, fmt.Errorf("no kustomization file found in %s", path)
+	}
+
+	tmpDir, err := ioutil.TempDir("", kustomization.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// kustomize only accepts relative resource paths
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	relPath, err := filepath.Rel(tmpDir, absPath)
+	if err != nil {
+		return nil, err
+	}
+	overlay, err := kustomizationOverlay(relPath, kustomization)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(overlay)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, konfig.DefaultKustomizationFileName()), data, 0644); err != nil {
+		return nil, err
+	}
+
+	opt := krusty.MakeDefaultOptions()
+	opt.DoLegacyResourceSort = true
+	opt.LoadRestrictions = kustypes.LoadRestrictionsNone
+	k := krusty.MakeKustomizer(fs, opt)
+	m, err := k.Run(tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("kustomize build failed: %w", err)
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, res := range m.Resources() {
+		objects = append(objects, &unstructured.Unstructured{Object: res.Map()})
+	}
+	return objects, nil
+}
+
+// kustomizationOverlay returns a kustomization that includes the given
+// directory and applies the spec of the Kustomization to it, the same
+// way kustomize-controller does.
+func kustomizationOverlay(path string, kustomization kustomizev1.Kustomization) (*kustypes.Kustomization, error) {
+	overlay := &kustypes.Kustomization{
+		TypeMeta: kustypes.TypeMeta{
+			APIVersion: kustypes.KustomizationVersion,
+			Kind:       kustypes.KustomizationKind,
+		},
+		Resources: []string{path},
+		Namespace: kustomization.Spec.TargetNamespace,
+	}
+
+	for _, patch := range kustomization.Spec.PatchesStrategicMerge {
+		overlay.PatchesStrategicMerge = append(overlay.PatchesStrategicMerge, kustypes.PatchStrategicMerge(patch.Raw))
+	}
+
+	for _, patch := range kustomization.Spec.PatchesJSON6902 {
+		data, err := json.Marshal(patch.Patch)
+		if err != nil {
+			return nil, err
+		}
+		overlay.PatchesJson6902 = append(overlay.PatchesJson6902, kustypes.Patch{
+			Patch: string(data),
+			Target: &kustypes.Selector{
+				Gvk: resid.Gvk{
+					Group:   patch.Target.Group,
+					Version: patch.Target.Version,
+					Kind:    patch.Target.Kind,
+				},
+				Namespace:          patch.Target.Namespace,
+				Name:               patch.Target.Name,
+				AnnotationSelector: patch.Target.AnnotationSelector,
+				LabelSelector:      patch.Target.LabelSelector,
+			},
+		})
+	}
+
+	for _, image := range kustomization.Spec.Images {
+		overlay.Images = append(overlay.Images, kustypes.Image{
+			Name:    image.Name,
+			NewName: image.NewName,
+			NewTag:  image.NewTag,
+			Digest:  image.Digest,
+		})
+	}
+
+	return overlay, nil
+}
+
+// readKustomizationFile returns the Kustomization with the given name
+// from a local file, which can contain multiple YAML documents.
+func readKustomizationFile(path, name string) (*kustomizev1.Kustomization, error) {
+	objects, err := readObjectFiles([]string{path})
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		if object.GetKind() != kustomizev1.KustomizationKind || object.GetName() != name {
+			continue
+		}
+		if ns := object.GetNamespace(); ns != "" && ns != rootArgs.namespace {
+			continue
+		}
+		var kustomization kustomizev1.Kustomization
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &kustomization); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if kustomization.Namespace == "" {
+			kustomization.Namespace = rootArgs.namespace
+		}
+		return &kustomization, nil
+	}
+	return nil, fmt.Errorf("Kustomization %s not found in %s", name, path)
+}
+
+// readObjectFiles decodes the YAML or JSON documents of the given files.
+func readObjectFiles(paths []string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		decoder := k8syaml.NewYAMLOrJSONDecoder(f, 4096)
+		for {
+			var object map[string]interface{}
+			err := decoder.Decode(&object)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if object != nil {
+				objects = append(objects, &unstructured.Unstructured{Object: object})
+			}
+		}
+		f.Close()
+	}
+	return objects, nil
+}
+
+// postBuildVars returns the variables of the post-build substitutions
+// of the Kustomization. The inline variables take precedence over the
+// ones from the referenced ConfigMaps and Secrets, which are looked up
+// in the given files first, and then in the cluster if a client is given.
+func postBuildVars(ctx context.Context, kubeClient client.Client,
+	kustomization kustomizev1.Kustomization, files []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	if kustomization.Spec.PostBuild == nil {
+		return vars, nil
+	}
+
+	local, err := readObjectFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range kustomization.Spec.PostBuild.SubstituteFrom {
+		values, err := substituteFromValues(ctx, kubeClient, kustomization.Namespace, ref, local)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range values {
+			vars[k] = v
+		}
+	}
+	for k, v := range kustomization.Spec.PostBuild.Substitute {
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+func substituteFromValues(ctx context.Context, kubeClient client.Client, namespace string,
+	ref kustomizev1.SubstituteReference, local []*unstructured.Unstructured) (map[string]string, error) {
+	var object *unstructured.Unstructured
+	for _, o := range local {
+		if o.GetKind() == ref.Kind && o.GetName() == ref.Name {
+			object = o
+			break
+		}
+	}
+	if object == nil {
+		if kubeClient == nil {
+			return nil, fmt.Errorf("substitute from '%s/%s' failed: not found in the files given with --substitute-from-file", ref.Kind, ref.Name)
+		}
+		object = &unstructured.Unstructured{}
+		object.SetAPIVersion("v1")
+		object.SetKind(ref.Kind)
+		namespacedName := types.NamespacedName{
+			Namespace: namespace,
+			Name:      ref.Name,
+		}
+		if err := kubeClient.Get(ctx, namespacedName, object); err != nil {
+			return nil, fmt.Errorf("substitute from '%s/%s' failed: %w", ref.Kind, ref.Name, err)
+		}
+	}
+
+	switch ref.Kind {
+	case "ConfigMap":
+		var configMap corev1.ConfigMap
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &configMap); err != nil {
+			return nil, fmt.Errorf("substitute from '%s/%s' failed: %w", ref.Kind, ref.Name, err)
+		}
+		return configMap.Data, nil
+	case "Secret":
+		var secret corev1.Secret
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &secret); err != nil {
+			return nil, fmt.Errorf("substitute from '%s/%s' failed: %w", ref.Kind, ref.Name, err)
+		}
+		values := make(map[string]string)
+		for k, v := range secret.Data {
+			values[k] = string(v)
+		}
+		for k, v := range secret.StringData {
+			values[k] = v
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("substitute from '%s/%s' failed: unsupported kind", ref.Kind, ref.Name)
+	}
+}
+
+// substituteVariables replaces the $var and ${var} references in the
+// object with the given values, unless substitutions are disabled for
+// the object. Defaults can be given with ${var:=default}, and variables
+// without a value are replaced with an empty string.
+func substituteVariables(object *unstructured.Unstructured, vars map[string]string) (*unstructured.Unstructured, error) {
+	if object.GetAnnotations()[substituteAnnotation] == substituteDisabledValue {
+		return object, nil
+	}
+
+	data, err := yaml.Marshal(object.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	unset := make(map[string]bool)
+	output := os.Expand(string(data), func(expr string) string {
+		name, value, hasDefault := expr, "", false
+		for _, op := range []string{":=", ":-"} {
+			if i := strings.Index(expr, op); i >= 0 {
+				name, value, hasDefault = expr[:i], expr[i+len(op):], true
+				break
+			}
+		}
+		if !substituteVarName.MatchString(name) {
+			return "$" + expr
+		}
+		if v, ok := vars[name]; ok && (v != "" || !hasDefault) {
+			return v
+		}
+		if !hasDefault {
+			unset[name] = true
+		}
+		return value
+	})
+
+	var names []string
+	for name := range unset {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		logger.Warningf("%s/%s: variable %s is not set, referenced in %s", object.GetKind(), objectKey(object), name,
+			strings.Join(variableLocations(object.Object, "", name), ", "))
+	}
+
+	var result map[string]interface{}
+	if err := yaml.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("%s/%s: variable substitution failed: %w", object.GetKind(), object.GetName(), err)
+	}
+	return &unstructured.Unstructured{Object: result}, nil
+}
+
+// objectKey returns the namespace/name of the object, or only its name
+// for cluster-scoped objects.
+func objectKey(object *unstructured.Unstructured) string {
+	if object.GetNamespace() == "" {
+		return object.GetName()
+	}
+	return object.GetNamespace() + "/" + object.GetName()
+}
+
+// variableLocations returns the field paths of the string values in the
+// object that reference the given variable.
+func variableLocations(value interface{}, path string, name string) []string {
+	var locations []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			locations = append(locations, variableLocations(v[key], field, name)...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			locations = append(locations, variableLocations(item, fmt.Sprintf("%s[%d]", path, i), name)...)
+		}
+	case string:
+		found := false
+		os.Expand(v, func(expr string) string {
+			if expr == name || strings.HasPrefix(expr, name+":") {
+				found = true
+			}
+			return ""
+		})
+		if found {
+			locations = append(locations, path)
+		}
+	}
+	return locations
+}
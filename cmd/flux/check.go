@@ -19,19 +19,24 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apimachineryversion "k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/fluxcd/pkg/version"
 
+	"github.com/fluxcd/flux2/internal/flags"
 	"github.com/fluxcd/flux2/internal/utils"
 	"github.com/fluxcd/flux2/pkg/manifestgen/install"
 	"github.com/fluxcd/flux2/pkg/status"
@@ -41,12 +46,19 @@ var checkCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check requirements and installation",
 	Long: `The check command will perform a series of checks to validate that
-the local environment is configured correctly and if the installed components are healthy.`,
+the local environment is configured correctly and if the installed components are healthy.
+With --output=json, the results are printed as a JSON report instead.`,
 	Example: `  # Run pre-installation checks
   flux check --pre
 
   # Run installation checks
   flux check
+
+  # Run installation checks including the image automation controllers
+  flux check --components-extra=image-reflector-controller,image-automation-controller
+
+  # Run installation checks and print a JSON report
+  flux check --output=json
 `,
 	RunE: runCheckCmd,
 }
@@ -55,6 +67,7 @@ type checkFlags struct {
 	pre             bool
 	components      []string
 	extraComponents []string
+	output          flags.CheckOutputFormat
 }
 
 type kubectlVersion struct {
@@ -70,46 +83,113 @@ func init() {
 		"list of components, accepts comma-separated values")
 	checkCmd.Flags().StringSliceVar(&checkArgs.extraComponents, "components-extra", nil,
 		"list of components in addition to those supplied or defaulted, accepts comma-separated values")
+	checkCmd.Flags().VarP(&checkArgs.output, "output", "o", checkArgs.output.Description())
 	rootCmd.AddCommand(checkCmd)
 }
 
+const (
+	checkPass = "pass"
+	checkFail = "fail"
+	checkWarn = "warn"
+)
+
+type checkResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// checkReport collects the results of the checks. The results are
+// logged as they are added, unless the report is printed as JSON.
+type checkReport struct {
+	Checks   []checkResult     `json:"checks"`
+	Healthy  bool              `json:"healthy"`
+	Versions map[string]string `json:"versions,omitempty"`
+
+	quiet bool
+}
+
+func (r *checkReport) add(name, status, message string) {
+	r.Checks = append(r.Checks, checkResult{Name: name, Status: status, Message: message})
+}
+
+func (r *checkReport) passf(name, format string, a ...interface{}) {
+	r.add(name, checkPass, fmt.Sprintf(format, a...))
+	if !r.quiet {
+		logger.Successf(format, a...)
+	}
+}
+
+func (r *checkReport) failf(name, format string, a ...interface{}) {
+	r.add(name, checkFail, fmt.Sprintf(format, a...))
+	if !r.quiet {
+		logger.Failuref(format, a...)
+	}
+}
+
+func (r *checkReport) warnf(name, format string, a ...interface{}) {
+	r.add(name, checkWarn, fmt.Sprintf(format, a...))
+	if !r.quiet {
+		logger.Warningf(format, a...)
+	}
+}
+
+func (r *checkReport) actionf(format string, a ...interface{}) {
+	if !r.quiet {
+		logger.Actionf(format, a...)
+	}
+}
+
+// done sets the overall health, prints the JSON report if requested
+// and exits with a non-zero code if a check failed.
+func (r *checkReport) done(failed bool, success string) error {
+	r.Healthy = !failed
+	if r.quiet {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else if !failed {
+		logger.Successf(success)
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
+
 func runCheckCmd(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	logger.Actionf("checking prerequisites")
+	report := &checkReport{quiet: checkArgs.output == "json"}
+
+	report.actionf("checking prerequisites")
 	checkFailed := false
 
-	fluxCheck()
+	fluxCheck(report)
 
-	if !kubectlCheck(ctx, ">=1.18.0-0") {
+	if !kubectlCheck(ctx, report, ">=1.18.0-0") {
 		checkFailed = true
 	}
 
-	if !kubernetesCheck(">=1.16.0-0") {
+	if !kubernetesCheck(report, ">=1.16.0-0") {
 		checkFailed = true
 	}
 
 	if checkArgs.pre {
-		if checkFailed {
-			os.Exit(1)
-		}
-		logger.Successf("prerequisites checks passed")
-		return nil
+		return report.done(checkFailed, "prerequisites checks passed")
 	}
 
-	logger.Actionf("checking controllers")
-	if !componentsCheck() {
+	report.actionf("checking controllers")
+	if !componentsCheck(report) {
 		checkFailed = true
 	}
-	if checkFailed {
-		os.Exit(1)
-	}
-	logger.Successf("all checks passed")
-	return nil
+	return report.done(checkFailed, "all checks passed")
 }
 
-func fluxCheck() {
+func fluxCheck(report *checkReport) {
 	curSv, err := version.ParseVersion(VERSION)
 	if err != nil {
 		return
@@ -127,91 +207,134 @@ func fluxCheck() {
 		return
 	}
 	if latestSv.GreaterThan(curSv) {
-		logger.Failuref("flux %s <%s (new version is available, please upgrade)", curSv, latestSv)
+		report.warnf("flux", "flux %s <%s (new version is available, please upgrade)", curSv, latestSv)
 	}
 }
 
-func kubectlCheck(ctx context.Context, constraint string) bool {
+func kubectlCheck(ctx context.Context, report *checkReport, constraint string) bool {
 	_, err := exec.LookPath("kubectl")
 	if err != nil {
-		logger.Failuref("kubectl not found")
+		report.failf("kubectl", "kubectl not found")
 		return false
 	}
 
 	kubectlArgs := []string{"version", "--client", "--output", "json"}
 	output, err := utils.ExecKubectlCommand(ctx, utils.ModeCapture, rootArgs.kubeconfig, rootArgs.kubecontext, kubectlArgs...)
 	if err != nil {
-		logger.Failuref("kubectl version can't be determined")
+		report.failf("kubectl", "kubectl version can't be determined")
 		return false
 	}
 
 	kv := &kubectlVersion{}
 	if err = json.Unmarshal([]byte(output), kv); err != nil {
-		logger.Failuref("kubectl version output can't be unmarshalled")
+		report.failf("kubectl", "kubectl version output can't be unmarshalled")
 		return false
 	}
 
 	v, err := version.ParseVersion(kv.ClientVersion.GitVersion)
 	if err != nil {
-		logger.Failuref("kubectl version can't be parsed")
+		report.failf("kubectl", "kubectl version can't be parsed")
 		return false
 	}
 
 	c, _ := semver.NewConstraint(constraint)
 	if !c.Check(v) {
-		logger.Failuref("kubectl version %s < %s", v.Original(), constraint)
+		report.failf("kubectl", "kubectl version %s < %s", v.Original(), constraint)
 		return false
 	}
 
-	logger.Successf("kubectl %s %s", v.String(), constraint)
+	report.passf("kubectl", "kubectl %s %s", v.String(), constraint)
 	return true
 }
 
-func kubernetesCheck(constraint string) bool {
+func kubernetesCheck(report *checkReport, constraint string) bool {
 	cfg, err := utils.KubeConfig(rootArgs.kubeconfig, rootArgs.kubecontext)
 	if err != nil {
-		logger.Failuref("Kubernetes client initialization failed: %s", err.Error())
+		report.failf("kubernetes", "Kubernetes client initialization failed: %s", err.Error())
 		return false
 	}
 
 	clientSet, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
-		logger.Failuref("Kubernetes client initialization failed: %s", err.Error())
+		report.failf("kubernetes", "Kubernetes client initialization failed: %s", err.Error())
 		return false
 	}
 
 	kv, err := clientSet.Discovery().ServerVersion()
 	if err != nil {
-		logger.Failuref("Kubernetes API call failed: %s", err.Error())
+		report.failf("kubernetes", "Kubernetes API call failed: %s", err.Error())
 		return false
 	}
 
 	v, err := version.ParseVersion(kv.String())
 	if err != nil {
-		logger.Failuref("Kubernetes version can't be determined")
+		report.failf("kubernetes", "Kubernetes version can't be determined")
 		return false
 	}
 
 	c, _ := semver.NewConstraint(constraint)
 	if !c.Check(v) {
-		logger.Failuref("Kubernetes version %s < %s", v.Original(), constraint)
+		report.failf("kubernetes", "Kubernetes version %s < %s", v.Original(), constraint)
 		return false
 	}
 
-	logger.Successf("Kubernetes %s %s", v.String(), constraint)
+	report.passf("kubernetes", "Kubernetes %s %s", v.String(), constraint)
 	return true
 }
 
-func componentsCheck() bool {
+// componentCRDs are the custom resource definitions installed with
+// each component.
+var componentCRDs = map[string][]string{
+	"source-controller": {
+		"buckets.source.toolkit.fluxcd.io",
+		"gitrepositories.source.toolkit.fluxcd.io",
+		"helmcharts.source.toolkit.fluxcd.io",
+		"helmrepositories.source.toolkit.fluxcd.io",
+	},
+	"kustomize-controller": {
+		"kustomizations.kustomize.toolkit.fluxcd.io",
+	},
+	"helm-controller": {
+		"helmreleases.helm.toolkit.fluxcd.io",
+	},
+	"notification-controller": {
+		"alerts.notification.toolkit.fluxcd.io",
+		"providers.notification.toolkit.fluxcd.io",
+		"receivers.notification.toolkit.fluxcd.io",
+	},
+	"image-reflector-controller": {
+		"imagepolicies.image.toolkit.fluxcd.io",
+		"imagerepositories.image.toolkit.fluxcd.io",
+	},
+	"image-automation-controller": {
+		"imageupdateautomations.image.toolkit.fluxcd.io",
+	},
+}
+
+// componentsCheck checks the deployments and the CRDs of the given
+// components, and the deployments of any other component found in the
+// namespace. Missing extra components are only reported as warnings.
+func componentsCheck(report *checkReport) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
+	components := append(checkArgs.components, checkArgs.extraComponents...)
+	if err := utils.ValidateComponents(components); err != nil {
+		report.failf("components", err.Error())
+		return false
+	}
+
 	kubeConfig, err := utils.KubeConfig(rootArgs.kubeconfig, rootArgs.kubecontext)
 	if err != nil {
 		return false
 	}
 
-	statusChecker, err := status.NewStatusChecker(kubeConfig, time.Second, rootArgs.timeout, logger)
+	// the status checker logs the status of each component by itself
+	var statusLogger = logger
+	if report.quiet {
+		statusLogger = stderrLogger{stderr: ioutil.Discard}
+	}
+	statusChecker, err := status.NewStatusChecker(kubeConfig, time.Second, rootArgs.timeout, statusLogger)
 	if err != nil {
 		return false
 	}
@@ -221,20 +344,89 @@ func componentsCheck() bool {
 		return false
 	}
 
-	ok := true
 	selector := client.MatchingLabels{"app.kubernetes.io/instance": rootArgs.namespace}
 	var list v1.DeploymentList
-	if err := kubeClient.List(ctx, &list, client.InNamespace(rootArgs.namespace), selector); err == nil {
-		for _, d := range list.Items {
-			if ref, err := buildComponentObjectRefs(d.Name); err == nil {
-				if err := statusChecker.Assess(ref...); err != nil {
-					ok = false
-				}
+	if err := kubeClient.List(ctx, &list, client.InNamespace(rootArgs.namespace), selector); err != nil {
+		report.failf("components", "listing the components failed: %s", err.Error())
+		return false
+	}
+	deployments := make(map[string]v1.Deployment)
+	for _, d := range list.Items {
+		deployments[d.Name] = d
+	}
+
+	ok := true
+	for _, name := range components {
+		d, found := deployments[name]
+		if !found {
+			if utils.ContainsItemString(checkArgs.extraComponents, name) {
+				report.warnf(name, "%s: deployment not found", name)
+			} else {
+				report.failf(name, "%s: deployment not found", name)
+				ok = false
+			}
+			continue
+		}
+		delete(deployments, name)
+
+		if !deploymentCheck(report, statusChecker, d) {
+			ok = false
+		}
+		for _, crd := range componentCRDs[name] {
+			var obj apiextensionsv1.CustomResourceDefinition
+			if err := kubeClient.Get(ctx, client.ObjectKey{Name: crd}, &obj); err != nil {
+				report.failf(name, "%s: CRD %s not found", name, crd)
+				ok = false
 			}
-			for _, c := range d.Spec.Template.Spec.Containers {
-				logger.Actionf(c.Image)
+		}
+	}
+
+	for _, d := range list.Items {
+		if _, found := deployments[d.Name]; found && !deploymentCheck(report, statusChecker, d) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// deploymentCheck checks the readiness of the deployment of a component,
+// and whether its version is compatible with the CLI.
+func deploymentCheck(report *checkReport, statusChecker *status.StatusChecker, d v1.Deployment) bool {
+	ok := true
+	if ref, err := buildComponentObjectRefs(d.Name); err == nil {
+		if err := statusChecker.Assess(ref...); err != nil {
+			report.add(d.Name, checkFail, "not ready")
+			ok = false
+		} else {
+			report.add(d.Name, checkPass, "ready")
+		}
+	}
+
+	if v := d.Labels["app.kubernetes.io/version"]; v != "" && !utils.CompatibleVersion(VERSION, v) {
+		report.warnf(d.Name, "%s: version %s is not compatible with flux %s", d.Name, v, VERSION)
+	}
+
+	for _, c := range d.Spec.Template.Spec.Containers {
+		report.actionf(c.Image)
+		if v := imageTag(c.Image); v != "" {
+			if report.Versions == nil {
+				report.Versions = make(map[string]string)
 			}
+			report.Versions[d.Name] = v
 		}
 	}
 	return ok
 }
+
+// imageTag returns the tag of a container image reference, or an
+// empty string if it has none.
+func imageTag(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
@@ -17,7 +17,15 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/flux2/internal/utils"
 )
 
 var completionCmd = &cobra.Command{
@@ -29,3 +37,46 @@ var completionCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(completionCmd)
 }
+
+// completionTimeout bounds the listing of the objects when completing
+// their names, so that the shell stays responsive.
+const completionTimeout = 5 * time.Second
+
+// resourceNamesCompletionFunc returns a completion function suggesting the
+// names of the objects of the list type, in the namespace and context given
+// on the command line. Nothing is suggested when the objects can't be listed.
+func resourceNamesCompletionFunc(list client.ObjectList) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+
+		// the get commands accept a list of contexts, of which the first is used
+		var kubecontext string
+		if contexts := splitContexts(rootArgs.kubecontext); len(contexts) > 0 {
+			kubecontext = contexts[0]
+		}
+		utils.KubeConnectRetries = 0
+		kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, kubecontext)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		list := list.DeepCopyObject().(client.ObjectList)
+		if err := kubeClient.List(ctx, list, client.InNamespace(rootArgs.namespace)); err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		items, err := apimeta.ExtractList(list)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var names []string
+		for _, item := range items {
+			name := item.(client.Object).GetName()
+			if strings.HasPrefix(name, toComplete) && !utils.ContainsItemString(args, name) {
+				names = append(names, name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
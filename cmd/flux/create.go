@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -27,8 +28,10 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/fluxcd/flux2/internal/flags"
 	"github.com/fluxcd/flux2/internal/utils"
 )
 
@@ -36,24 +39,101 @@ var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create or update sources and resources",
 	Long:  "The create sub-commands generate sources and resources.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := rootCmd.PersistentPreRunE(cmd, args); err != nil {
+			return err
+		}
+		if createArgs.export && serverDryRun() {
+			return fmt.Errorf("--export and --dry-run=server are mutually exclusive")
+		}
+		if createArgs.forceConflicts && !createArgs.serverSide {
+			return fmt.Errorf("--force-conflicts can only be used with --server-side")
+		}
+		return nil
+	},
 }
 
 type createFlags struct {
-	interval time.Duration
-	export   bool
-	labels   []string
+	interval       time.Duration
+	export         bool
+	dryRun         flags.DryRunStrategy
+	labels         []string
+	serverSide     bool
+	forceConflicts bool
 }
 
-var createArgs createFlags
+var createArgs = NewCreateFlags()
+
+// createFieldManager is the field manager used for server-side apply.
+const createFieldManager = "flux-cli"
 
 func init() {
 	createCmd.PersistentFlags().DurationVarP(&createArgs.interval, "interval", "", time.Minute, "source sync interval")
 	createCmd.PersistentFlags().BoolVar(&createArgs.export, "export", false, "export in YAML format to stdout")
+	createCmd.PersistentFlags().Var(&createArgs.dryRun, "dry-run", createArgs.dryRun.Description())
 	createCmd.PersistentFlags().StringSliceVar(&createArgs.labels, "label", nil,
 		"set labels on the resource (can specify multiple labels with commas: label1=value1,label2=value2)")
+	createCmd.PersistentFlags().BoolVar(&createArgs.serverSide, "server-side", false,
+		"apply the objects with server-side apply, using "+createFieldManager+" as the field manager")
+	createCmd.PersistentFlags().BoolVar(&createArgs.forceConflicts, "force-conflicts", false,
+		"take ownership of the fields managed by other field managers, requires --server-side")
 	rootCmd.AddCommand(createCmd)
 }
 
+func NewCreateFlags() createFlags {
+	return createFlags{
+		dryRun: flags.DryRunStrategy("none"),
+	}
+}
+
+// serverDryRun returns true if the objects are to be validated by the
+// API server instead of being applied.
+func serverDryRun() bool {
+	return createArgs.dryRun == "server"
+}
+
+// createKubeClient returns the client used to apply the generated objects.
+// With --dry-run=server, the writes are submitted as dry runs, which run
+// the admission webhooks and the validation without persisting the objects.
+func createKubeClient() (client.Client, error) {
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return nil, err
+	}
+	if serverDryRun() {
+		logger.Actionf("running a server side dry run, the objects are not persisted")
+		return client.NewDryRunClient(kubeClient), nil
+	}
+	return kubeClient, nil
+}
+
+// applyServerSide applies the object with server-side apply. The object
+// holds the full desired state, fields that are omitted are released
+// by the field manager. On success, the object is updated with the
+// response of the API server.
+func applyServerSide(ctx context.Context, kubeClient client.Client, object client.Object) error {
+	gvk, err := apiutil.GVKForObject(object, utils.NewScheme())
+	if err != nil {
+		return err
+	}
+	object.GetObjectKind().SetGroupVersionKind(gvk)
+	object.SetResourceVersion("")
+	object.SetManagedFields(nil)
+
+	opts := []client.PatchOption{client.FieldOwner(createFieldManager)}
+	if createArgs.forceConflicts {
+		opts = append(opts, client.ForceOwnership)
+	}
+	return kubeClient.Patch(ctx, object, client.Apply, opts...)
+}
+
+// printDryRun prints the object as it would be applied, in place of
+// waiting for its reconciliation.
+func printDryRun(export interface{}) error {
+	logger.Successf("dry run completed, the object is valid")
+	return printExport(os.Stdout, export, "yaml")
+}
+
 // upsertable is an interface for values that can be used in `upsert`.
 type upsertable interface {
 	adapter
@@ -78,7 +158,22 @@ func (names apiType) upsert(ctx context.Context, kubeClient client.Client, objec
 		Name:      object.GetName(),
 	}
 
-	op, err := controllerutil.CreateOrUpdate(ctx, kubeClient, object.asClientObject(), mutate)
+	if createArgs.serverSide {
+		if err := mutate(); err != nil {
+			return nsname, err
+		}
+		if err := applyServerSide(ctx, kubeClient, object.asClientObject()); err != nil {
+			return nsname, err
+		}
+		logger.Successf("%s applied", names.kind)
+		return nsname, nil
+	}
+
+	var resourceVersion string
+	op, err := controllerutil.CreateOrUpdate(ctx, kubeClient, object.asClientObject(), func() error {
+		resourceVersion = object.asClientObject().GetResourceVersion()
+		return mutate()
+	})
 	if err != nil {
 		return nsname, err
 	}
@@ -87,11 +182,26 @@ func (names apiType) upsert(ctx context.Context, kubeClient client.Client, objec
 	case controllerutil.OperationResultCreated:
 		logger.Successf("%s created", names.kind)
 	case controllerutil.OperationResultUpdated:
-		logger.Successf("%s updated", names.kind)
+		logUpdateResult(names.kind, resourceVersion, object.asClientObject())
+	case controllerutil.OperationResultNone:
+		logger.Successf("%s unchanged", names.kind)
 	}
 	return nsname, nil
 }
 
+// logUpdateResult reports whether the update changed the object. The
+// API server keeps the resource version when the update makes no
+// difference, e.g. when the differences are only fields that are
+// defaulted by the server. A dry run never changes the resource version,
+// so it is always reported as an update.
+func logUpdateResult(kind, previousVersion string, object client.Object) {
+	if !serverDryRun() && object.GetResourceVersion() == previousVersion {
+		logger.Successf("%s unchanged", kind)
+		return
+	}
+	logger.Successf("%s updated", kind)
+}
+
 type upsertWaitable interface {
 	upsertable
 	statusable
@@ -104,7 +214,7 @@ func (names apiType) upsertAndWait(object upsertWaitable, mutate func() error) e
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext) // NB globals
+	kubeClient, err := createKubeClient() // NB globals
 	if err != nil {
 		return err
 	}
@@ -117,6 +227,10 @@ func (names apiType) upsertAndWait(object upsertWaitable, mutate func() error) e
 		return err
 	}
 
+	if e, ok := object.(exportable); ok && serverDryRun() {
+		return printDryRun(e.export())
+	}
+
 	logger.Waitingf("waiting for %s reconciliation", names.kind)
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isReady(ctx, kubeClient, namespacedName, object)); err != nil {
@@ -127,8 +241,13 @@ func (names apiType) upsertAndWait(object upsertWaitable, mutate func() error) e
 }
 
 func parseLabels() (map[string]string, error) {
+	return parseLabelPairs(createArgs.labels)
+}
+
+// parseLabelPairs parses and validates a list of key=value labels.
+func parseLabelPairs(labels []string) (map[string]string, error) {
 	result := make(map[string]string)
-	for _, label := range createArgs.labels {
+	for _, label := range labels {
 		// validate key value pair
 		parts := strings.Split(label, "=")
 		if len(parts) != 2 {
@@ -19,8 +19,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +34,7 @@ import (
 	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 
+	"github.com/fluxcd/flux2/internal/flags"
 	"github.com/fluxcd/flux2/internal/utils"
 )
 
@@ -44,22 +48,45 @@ var createAlertCmd = &cobra.Command{
   --event-source Kustomization/flux-system \
   --provider-ref slack \
   flux-system
+
+  # Create an Alert for the failures of all Git repositories
+  flux create alert git-failures \
+  --event-severity error \
+  --event-source 'GitRepository/*' \
+  --provider-ref slack \
+  --summary "Git sync failed in the staging cluster"
 `,
 	RunE: createAlertCmdRun,
 }
 
 type alertFlags struct {
 	providerRef   string
-	eventSeverity string
+	eventSeverity flags.EventSeverity
 	eventSources  []string
+	summary       string
+}
+
+// alertEventSourceKinds are the kinds of the objects that an Alert
+// can receive events from.
+var alertEventSourceKinds = []string{
+	bucketType.kind,
+	gitRepositoryType.kind,
+	kustomizationType.kind,
+	helmReleaseType.kind,
+	helmChartType.kind,
+	helmRepositoryType.kind,
+	imageRepositoryType.kind,
+	imagePolicyType.kind,
+	imageUpdateAutomationType.kind,
 }
 
 var alertArgs alertFlags
 
 func init() {
 	createAlertCmd.Flags().StringVar(&alertArgs.providerRef, "provider-ref", "", "reference to provider")
-	createAlertCmd.Flags().StringVar(&alertArgs.eventSeverity, "event-severity", "", "severity of events to send alerts for")
-	createAlertCmd.Flags().StringArrayVar(&alertArgs.eventSources, "event-source", []string{}, "sources that should generate alerts (<kind>/<name>)")
+	createAlertCmd.Flags().Var(&alertArgs.eventSeverity, "event-severity", alertArgs.eventSeverity.Description())
+	createAlertCmd.Flags().StringArrayVar(&alertArgs.eventSources, "event-source", []string{}, "sources that should generate alerts (<kind>/<name>), the name can be '*' to match all objects of a kind")
+	createAlertCmd.Flags().StringVar(&alertArgs.summary, "summary", "", "short description of the impact and affected cluster, included in the alerts")
 	createCmd.AddCommand(createAlertCmd)
 }
 
@@ -79,6 +106,12 @@ func createAlertCmdRun(cmd *cobra.Command, args []string) error {
 		if kind == "" {
 			return fmt.Errorf("invalid event source '%s', must be in format <kind>/<name>", eventSource)
 		}
+		k, ok := utils.ContainsEqualFoldItemString(alertEventSourceKinds, kind)
+		if !ok {
+			return fmt.Errorf("invalid event source '%s', kind must be one of: %s",
+				eventSource, strings.Join(alertEventSourceKinds, ", "))
+		}
+		kind = k
 
 		eventSources = append(eventSources, notificationv1.CrossNamespaceObjectReference{
 			Kind: kind,
@@ -109,21 +142,32 @@ func createAlertCmdRun(cmd *cobra.Command, args []string) error {
 			ProviderRef: meta.LocalObjectReference{
 				Name: alertArgs.providerRef,
 			},
-			EventSeverity: alertArgs.eventSeverity,
+			EventSeverity: alertArgs.eventSeverity.String(),
 			EventSources:  eventSources,
+			Summary:       alertArgs.summary,
 			Suspend:       false,
 		},
 	}
 
 	if createArgs.export {
-		return exportAlert(alert)
+		return printExport(os.Stdout, exportAlert(&alert), "yaml")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
+	if err != nil {
+		return err
+	}
+
+	var provider notificationv1.Provider
+	err = kubeClient.Get(ctx, types.NamespacedName{Namespace: rootArgs.namespace, Name: alertArgs.providerRef}, &provider)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("Provider '%s' not found in %s namespace, create it first with 'flux create alert-provider'",
+				alertArgs.providerRef, rootArgs.namespace)
+		}
 		return err
 	}
 
@@ -133,6 +177,10 @@ func createAlertCmdRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if serverDryRun() {
+		return printDryRun(exportAlert(&alert))
+	}
+
 	logger.Waitingf("waiting for Alert reconciliation")
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isAlertReady(ctx, kubeClient, namespacedName, &alert)); err != nil {
@@ -149,6 +197,14 @@ func upsertAlert(ctx context.Context, kubeClient client.Client,
 		Name:      alert.GetName(),
 	}
 
+	if createArgs.serverSide {
+		if err := applyServerSide(ctx, kubeClient, alert); err != nil {
+			return namespacedName, err
+		}
+		logger.Successf("Alert applied")
+		return namespacedName, nil
+	}
+
 	var existing notificationv1.Alert
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -163,13 +219,20 @@ func upsertAlert(ctx context.Context, kubeClient client.Client,
 		return namespacedName, err
 	}
 
+	if equality.Semantic.DeepEqual(existing.Labels, alert.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec, alert.Spec) {
+		logger.Successf("Alert unchanged")
+		return namespacedName, nil
+	}
+
+	resourceVersion := existing.ResourceVersion
 	existing.Labels = alert.Labels
 	existing.Spec = alert.Spec
 	if err := kubeClient.Update(ctx, &existing); err != nil {
 		return namespacedName, err
 	}
 	alert = &existing
-	logger.Successf("Alert updated")
+	logUpdateResult("Alert", resourceVersion, &existing)
 	return namespacedName, nil
 }
 
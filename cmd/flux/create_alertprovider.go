@@ -19,8 +19,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"os"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,7 +34,7 @@ import (
 	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 
-	"github.com/fluxcd/flux2/internal/utils"
+	"github.com/fluxcd/flux2/internal/flags"
 )
 
 var createAlertProviderCmd = &cobra.Command{
@@ -50,12 +53,17 @@ var createAlertProviderCmd = &cobra.Command{
   --type github \
   --address https://github.com/stefanprodan/podinfo \
   --secret-ref github-token
+
+  # Create a Provider for a generic webhook
+  flux create alert-provider webhook \
+  --type generic \
+  --address https://example.com/flux-events
 `,
 	RunE: createAlertProviderCmdRun,
 }
 
 type alertProviderFlags struct {
-	alertType string
+	alertType flags.AlertProviderType
 	channel   string
 	username  string
 	address   string
@@ -65,7 +73,7 @@ type alertProviderFlags struct {
 var alertProviderArgs alertProviderFlags
 
 func init() {
-	createAlertProviderCmd.Flags().StringVar(&alertProviderArgs.alertType, "type", "", "type of provider")
+	createAlertProviderCmd.Flags().Var(&alertProviderArgs.alertType, "type", alertProviderArgs.alertType.Description())
 	createAlertProviderCmd.Flags().StringVar(&alertProviderArgs.channel, "channel", "", "channel to send messages to in the case of a chat provider")
 	createAlertProviderCmd.Flags().StringVar(&alertProviderArgs.username, "username", "", "bot username used by the provider")
 	createAlertProviderCmd.Flags().StringVar(&alertProviderArgs.address, "address", "", "path to either the git repository, chat provider or webhook")
@@ -83,6 +91,10 @@ func createAlertProviderCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("Provider type is required")
 	}
 
+	if err := validateAlertProviderArgs(); err != nil {
+		return err
+	}
+
 	sourceLabels, err := parseLabels()
 	if err != nil {
 		return err
@@ -99,7 +111,7 @@ func createAlertProviderCmdRun(cmd *cobra.Command, args []string) error {
 			Labels:    sourceLabels,
 		},
 		Spec: notificationv1.ProviderSpec{
-			Type:     alertProviderArgs.alertType,
+			Type:     alertProviderArgs.alertType.String(),
 			Channel:  alertProviderArgs.channel,
 			Username: alertProviderArgs.username,
 			Address:  alertProviderArgs.address,
@@ -113,13 +125,13 @@ func createAlertProviderCmdRun(cmd *cobra.Command, args []string) error {
 	}
 
 	if createArgs.export {
-		return exportAlertProvider(provider)
+		return printExport(os.Stdout, exportAlertProvider(&provider), "yaml")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
@@ -130,6 +142,10 @@ func createAlertProviderCmdRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if serverDryRun() {
+		return printDryRun(exportAlertProvider(&provider))
+	}
+
 	logger.Waitingf("waiting for Provider reconciliation")
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isAlertProviderReady(ctx, kubeClient, namespacedName, &provider)); err != nil {
@@ -141,6 +157,49 @@ func createAlertProviderCmdRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// validateAlertProviderArgs checks the flags required by the provider
+// type. The chat providers can read their webhook address from the
+// address key of the secret, the git providers need a token secret.
+func validateAlertProviderArgs() error {
+	providerType := alertProviderArgs.alertType.String()
+	switch providerType {
+	case notificationv1.SlackProvider, notificationv1.DiscordProvider, notificationv1.MSTeamsProvider:
+		if alertProviderArgs.address == "" && alertProviderArgs.secretRef == "" {
+			return fmt.Errorf("the %s provider requires --address, or --secret-ref with the webhook address", providerType)
+		}
+		if alertProviderArgs.channel == "" {
+			return fmt.Errorf("the %s provider requires --channel", providerType)
+		}
+	case notificationv1.RocketProvider, notificationv1.GoogleChatProvider:
+		if alertProviderArgs.address == "" && alertProviderArgs.secretRef == "" {
+			return fmt.Errorf("the %s provider requires --address, or --secret-ref with the webhook address", providerType)
+		}
+	case notificationv1.GitHubProvider, notificationv1.GitLabProvider,
+		notificationv1.BitbucketProvider, notificationv1.AzureDevOpsProvider:
+		if alertProviderArgs.address == "" {
+			return fmt.Errorf("the %s provider requires --address with the repository URL", providerType)
+		}
+		if alertProviderArgs.secretRef == "" {
+			return fmt.Errorf("the %s provider requires --secret-ref with the API token", providerType)
+		}
+	case notificationv1.GenericProvider:
+		if alertProviderArgs.address == "" {
+			return fmt.Errorf("the %s provider requires --address", providerType)
+		}
+	}
+
+	if alertProviderArgs.address != "" {
+		u, err := url.Parse(alertProviderArgs.address)
+		if err != nil {
+			return fmt.Errorf("invalid address '%s': %w", alertProviderArgs.address, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid address '%s', must be an http or https URL", alertProviderArgs.address)
+		}
+	}
+	return nil
+}
+
 func upsertAlertProvider(ctx context.Context, kubeClient client.Client,
 	provider *notificationv1.Provider) (types.NamespacedName, error) {
 	namespacedName := types.NamespacedName{
@@ -148,6 +207,14 @@ func upsertAlertProvider(ctx context.Context, kubeClient client.Client,
 		Name:      provider.GetName(),
 	}
 
+	if createArgs.serverSide {
+		if err := applyServerSide(ctx, kubeClient, provider); err != nil {
+			return namespacedName, err
+		}
+		logger.Successf("Provider applied")
+		return namespacedName, nil
+	}
+
 	var existing notificationv1.Provider
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -162,13 +229,20 @@ func upsertAlertProvider(ctx context.Context, kubeClient client.Client,
 		return namespacedName, err
 	}
 
+	if equality.Semantic.DeepEqual(existing.Labels, provider.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec, provider.Spec) {
+		logger.Successf("Provider unchanged")
+		return namespacedName, nil
+	}
+
+	resourceVersion := existing.ResourceVersion
 	existing.Labels = provider.Labels
 	existing.Spec = provider.Spec
 	if err := kubeClient.Update(ctx, &existing); err != nil {
 		return namespacedName, err
 	}
 	provider = &existing
-	logger.Successf("Provider updated")
+	logUpdateResult("Provider", resourceVersion, &existing)
 	return namespacedName, nil
 }
 
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 
 	"github.com/fluxcd/flux2/internal/flags"
 	"github.com/fluxcd/flux2/internal/utils"
@@ -29,6 +30,7 @@ import (
 
 	"github.com/spf13/cobra"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -79,6 +81,13 @@ var createHelmReleaseCmd = &cobra.Command{
     --chart=podinfo \
     --values-from=Secret/my-secret-values
 
+  # Create a HelmRelease with values from a key of a ConfigMap, overridden by an optional Secret
+  flux -n app create hr podinfo \
+    --source=HelmRepository/podinfo \
+    --chart=podinfo \
+    --values-from=ConfigMap/my-values:values-prod.yaml \
+    --values-from=Secret/my-secret-values+optional
+
   # Create a HelmRelease with a custom release name
   flux create hr podinfo \
     --release-name=podinfo-dev
@@ -91,6 +100,17 @@ var createHelmReleaseCmd = &cobra.Command{
     --source=HelmRepository/podinfo \
     --chart=podinfo
 
+  # Create a HelmRelease that creates its target namespace, skips the chart CRDs
+  # and retries failed installs and upgrades three times
+  flux create hr podinfo \
+    --target-namespace=podinfo \
+    --create-target-namespace \
+    --install-crds=none \
+    --remediation-retries=3 \
+    --remediate-last-failure \
+    --source=HelmRepository/podinfo \
+    --chart=podinfo
+
   # Create a HelmRelease definition on disk without applying it on the cluster
   flux create hr podinfo \
     --source=HelmRepository/podinfo \
@@ -109,8 +129,13 @@ type helmReleaseFlags struct {
 	chartVersion    string
 	targetNamespace string
 	valuesFile      []string
-	valuesFrom      flags.HelmReleaseValuesFrom
+	valuesFrom      []string
 	saName          string
+
+	installCRDs           flags.CRDsPolicy
+	createTargetNamespace bool
+	remediationRetries    int
+	remediateLastFailure  bool
 }
 
 var helmReleaseArgs helmReleaseFlags
@@ -124,7 +149,15 @@ func init() {
 	createHelmReleaseCmd.Flags().StringVar(&helmReleaseArgs.targetNamespace, "target-namespace", "", "namespace to install this release, defaults to the HelmRelease namespace")
 	createHelmReleaseCmd.Flags().StringVar(&helmReleaseArgs.saName, "service-account", "", "the name of the service account to impersonate when reconciling this HelmRelease")
 	createHelmReleaseCmd.Flags().StringArrayVar(&helmReleaseArgs.valuesFile, "values", nil, "local path to values.yaml files")
-	createHelmReleaseCmd.Flags().Var(&helmReleaseArgs.valuesFrom, "values-from", helmReleaseArgs.valuesFrom.Description())
+	createHelmReleaseCmd.Flags().StringArrayVar(&helmReleaseArgs.valuesFrom, "values-from", nil,
+		(&flags.HelmReleaseValuesFrom{}).Description()+", may be repeated with the later references overriding the earlier ones")
+	createHelmReleaseCmd.Flags().Var(&helmReleaseArgs.installCRDs, "install-crds", helmReleaseArgs.installCRDs.Description())
+	createHelmReleaseCmd.Flags().BoolVar(&helmReleaseArgs.createTargetNamespace, "create-target-namespace", false,
+		"create the target namespace of the release if it doesn't exist")
+	createHelmReleaseCmd.Flags().IntVar(&helmReleaseArgs.remediationRetries, "remediation-retries", 0,
+		"the number of retries of a failed install or upgrade, the release is uninstalled or rolled back before each retry")
+	createHelmReleaseCmd.Flags().BoolVar(&helmReleaseArgs.remediateLastFailure, "remediate-last-failure", false,
+		"remediate the last failure of the install or upgrade once the retries are exhausted, defaults to true for upgrades with retries")
 	createCmd.AddCommand(createHelmReleaseCmd)
 }
 
@@ -138,6 +171,10 @@ func createHelmReleaseCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("chart name or path is required")
 	}
 
+	if helmReleaseArgs.remediationRetries < 0 {
+		return fmt.Errorf("remediation-retries must not be negative")
+	}
+
 	sourceLabels, err := parseLabels()
 	if err != nil {
 		return err
@@ -178,6 +215,8 @@ func createHelmReleaseCmdRun(cmd *cobra.Command, args []string) error {
 		helmRelease.Spec.ServiceAccountName = helmReleaseArgs.saName
 	}
 
+	setHelmReleaseLifecycle(cmd, &helmRelease)
+
 	if len(helmReleaseArgs.valuesFile) > 0 {
 		var valuesMap map[string]interface{}
 		for _, v := range helmReleaseArgs.valuesFile {
@@ -211,21 +250,28 @@ func createHelmReleaseCmdRun(cmd *cobra.Command, args []string) error {
 		helmRelease.Spec.Values = &apiextensionsv1.JSON{Raw: jsonRaw}
 	}
 
-	if helmReleaseArgs.valuesFrom.String() != "" {
-		helmRelease.Spec.ValuesFrom = []helmv2.ValuesReference{{
-			Kind: helmReleaseArgs.valuesFrom.Kind,
-			Name: helmReleaseArgs.valuesFrom.Name,
-		}}
+	// the order is kept, as the later references override the earlier ones
+	for _, str := range helmReleaseArgs.valuesFrom {
+		var valuesFrom flags.HelmReleaseValuesFrom
+		if err := valuesFrom.Set(str); err != nil {
+			return fmt.Errorf("invalid values-from '%s': %w", str, err)
+		}
+		helmRelease.Spec.ValuesFrom = append(helmRelease.Spec.ValuesFrom, helmv2.ValuesReference{
+			Kind:      valuesFrom.Kind,
+			Name:      valuesFrom.Name,
+			ValuesKey: valuesFrom.ValuesKey,
+			Optional:  valuesFrom.Optional,
+		})
 	}
 
 	if createArgs.export {
-		return exportHelmRelease(helmRelease)
+		return printExport(os.Stdout, exportHelmRelease(&helmRelease), "yaml")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
@@ -236,6 +282,10 @@ func createHelmReleaseCmdRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if serverDryRun() {
+		return printDryRun(exportHelmRelease(&helmRelease))
+	}
+
 	logger.Waitingf("waiting for HelmRelease reconciliation")
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isHelmReleaseReady(ctx, kubeClient, namespacedName, &helmRelease)); err != nil {
@@ -247,6 +297,38 @@ func createHelmReleaseCmdRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// setHelmReleaseLifecycle sets the install and upgrade specs from the
+// lifecycle flags, leaving them unset when none of the flags are given.
+func setHelmReleaseLifecycle(cmd *cobra.Command, helmRelease *helmv2.HelmRelease) {
+	var remediateLastFailure *bool
+	if cmd.Flags().Changed("remediate-last-failure") {
+		remediateLastFailure = &helmReleaseArgs.remediateLastFailure
+	}
+	remediate := helmReleaseArgs.remediationRetries > 0 || remediateLastFailure != nil
+
+	if helmReleaseArgs.installCRDs != "" || helmReleaseArgs.createTargetNamespace || remediate {
+		helmRelease.Spec.Install = &helmv2.Install{
+			SkipCRDs:        helmReleaseArgs.installCRDs.String() == flags.CRDsPolicySkip,
+			CreateNamespace: helmReleaseArgs.createTargetNamespace,
+		}
+		if remediate {
+			helmRelease.Spec.Install.Remediation = &helmv2.InstallRemediation{
+				Retries:              helmReleaseArgs.remediationRetries,
+				RemediateLastFailure: remediateLastFailure,
+			}
+		}
+	}
+
+	if remediate {
+		helmRelease.Spec.Upgrade = &helmv2.Upgrade{
+			Remediation: &helmv2.UpgradeRemediation{
+				Retries:              helmReleaseArgs.remediationRetries,
+				RemediateLastFailure: remediateLastFailure,
+			},
+		}
+	}
+}
+
 func upsertHelmRelease(ctx context.Context, kubeClient client.Client,
 	helmRelease *helmv2.HelmRelease) (types.NamespacedName, error) {
 	namespacedName := types.NamespacedName{
@@ -254,6 +336,14 @@ func upsertHelmRelease(ctx context.Context, kubeClient client.Client,
 		Name:      helmRelease.GetName(),
 	}
 
+	if createArgs.serverSide {
+		if err := applyServerSide(ctx, kubeClient, helmRelease); err != nil {
+			return namespacedName, err
+		}
+		logger.Successf("HelmRelease applied")
+		return namespacedName, nil
+	}
+
 	var existing helmv2.HelmRelease
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -268,13 +358,20 @@ func upsertHelmRelease(ctx context.Context, kubeClient client.Client,
 		return namespacedName, err
 	}
 
+	if equality.Semantic.DeepEqual(existing.Labels, helmRelease.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec, helmRelease.Spec) {
+		logger.Successf("HelmRelease unchanged")
+		return namespacedName, nil
+	}
+
+	resourceVersion := existing.ResourceVersion
 	existing.Labels = helmRelease.Labels
 	existing.Spec = helmRelease.Spec
 	if err := kubeClient.Update(ctx, &existing); err != nil {
 		return namespacedName, err
 	}
 	helmRelease = &existing
-	logger.Successf("HelmRelease updated")
+	logUpdateResult("HelmRelease", resourceVersion, &existing)
 	return namespacedName, nil
 }
 
@@ -18,11 +18,13 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"regexp/syntax"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -113,11 +115,15 @@ func createImagePolicyRun(cmd *cobra.Command, args []string) error {
 	}
 
 	switch {
-	case imagePolicyArgs.semver != "" && imagePolicyArgs.alpha != "":
-	case imagePolicyArgs.semver != "" && imagePolicyArgs.numeric != "":
-	case imagePolicyArgs.alpha != "" && imagePolicyArgs.numeric != "":
+	case imagePolicyArgs.semver != "" && imagePolicyArgs.alpha != "",
+		imagePolicyArgs.semver != "" && imagePolicyArgs.numeric != "",
+		imagePolicyArgs.alpha != "" && imagePolicyArgs.numeric != "":
 		return fmt.Errorf("only one of --select-semver, --select-alpha or --select-numeric can be specified")
 	case imagePolicyArgs.semver != "":
+		// the controller parses the range with the same library
+		if _, err := semver.NewConstraint(imagePolicyArgs.semver); err != nil {
+			return fmt.Errorf("--select-semver is an invalid semver range: %w", err)
+		}
 		policy.Spec.Policy.SemVer = &imagev1.SemVerPolicy{
 			Range: imagePolicyArgs.semver,
 		}
@@ -136,7 +142,7 @@ func createImagePolicyRun(cmd *cobra.Command, args []string) error {
 			Order: imagePolicyArgs.numeric,
 		}
 	default:
-		return fmt.Errorf("a policy must be provided with either --select-semver or --select-alpha")
+		return fmt.Errorf("a policy must be provided with either --select-semver, --select-alpha or --select-numeric")
 	}
 
 	if imagePolicyArgs.filterRegex != "" {
@@ -159,7 +165,7 @@ func createImagePolicyRun(cmd *cobra.Command, args []string) error {
 	}
 
 	if createArgs.export {
-		return printExport(exportImagePolicy(&policy))
+		return printExport(os.Stdout, exportImagePolicy(&policy), "yaml")
 	}
 
 	var existing imagev1.ImagePolicy
@@ -18,6 +18,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -37,6 +38,11 @@ An ImageRepository object specifies an image repository to scan.`,
 	Example: `  # Create an ImageRepository object to scan the alpine image repository:
   flux create image repository alpine-repo --image alpine --interval 20m
 
+  # Create an ImageRepository object which scans the image every hour,
+  # with a scan timeout of one minute:
+  flux create image repository alpine-repo --image alpine \
+    --scan-interval 1h --scan-timeout 1m
+
   # Create an image repository that uses an image pull secret (assumed to
   # have been created already):
   flux create image repository myapp-repo \
@@ -67,6 +73,7 @@ type imageRepoFlags struct {
 	secretRef     string
 	certSecretRef string
 	timeout       time.Duration
+	scanInterval  time.Duration
 }
 
 var imageRepoArgs = imageRepoFlags{}
@@ -75,7 +82,10 @@ func init() {
 	flags := createImageRepositoryCmd.Flags()
 	flags.StringVar(&imageRepoArgs.image, "image", "", "the image repository to scan; e.g., library/alpine")
 	flags.StringVar(&imageRepoArgs.secretRef, "secret-ref", "", "the name of a docker-registry secret to use for credentials")
+	flags.StringVar(&imageRepoArgs.certSecretRef, "cert-secret-ref", "", "the name of a secret to use for TLS certificates")
 	flags.StringVar(&imageRepoArgs.certSecretRef, "cert-ref", "", "the name of a secret to use for TLS certificates")
+	flags.MarkDeprecated("cert-ref", "use --cert-secret-ref instead")
+	flags.DurationVar(&imageRepoArgs.scanInterval, "scan-interval", 0, "the interval at which the image repository is scanned; this defaults to --interval if not set")
 	// NB there is already a --timeout in the global flags, for
 	// controlling timeout on operations while e.g., creating objects.
 	flags.DurationVar(&imageRepoArgs.timeout, "scan-timeout", 0, "a timeout for scanning; this defaults to the interval if not set")
@@ -97,6 +107,14 @@ func createImageRepositoryRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unable to parse image value: %w", err)
 	}
 
+	interval := createArgs.interval
+	if cmd.Flags().Changed("scan-interval") {
+		if imageRepoArgs.scanInterval <= 0 {
+			return fmt.Errorf("--scan-interval must be a positive duration")
+		}
+		interval = imageRepoArgs.scanInterval
+	}
+
 	labels, err := parseLabels()
 	if err != nil {
 		return err
@@ -110,7 +128,7 @@ func createImageRepositoryRun(cmd *cobra.Command, args []string) error {
 		},
 		Spec: imagev1.ImageRepositorySpec{
 			Image:    imageRepoArgs.image,
-			Interval: metav1.Duration{Duration: createArgs.interval},
+			Interval: metav1.Duration{Duration: interval},
 		},
 	}
 	if imageRepoArgs.timeout != 0 {
@@ -128,7 +146,7 @@ func createImageRepositoryRun(cmd *cobra.Command, args []string) error {
 	}
 
 	if createArgs.export {
-		return printExport(exportImageRepository(&repo))
+		return printExport(os.Stdout, exportImageRepository(&repo), "yaml")
 	}
 
 	// a temp value for use with the rest
@@ -18,6 +18,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -145,7 +146,7 @@ func createImageUpdateRun(cmd *cobra.Command, args []string) error {
 	}
 
 	if createArgs.export {
-		return printExport(exportImageUpdate(&update))
+		return printExport(os.Stdout, exportImageUpdate(&update), "yaml")
 	}
 
 	var existing autov1.ImageUpdateAutomation
@@ -18,21 +18,28 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	"github.com/fluxcd/pkg/apis/kustomize"
 	"github.com/fluxcd/pkg/apis/meta"
 
 	"github.com/fluxcd/flux2/internal/flags"
@@ -69,6 +76,36 @@ var createKsCmd = &cobra.Command{
     --source=Bucket/secrets \
     --prune=true \
     --interval=5m
+
+  # Create a Kustomization resource that retries failed applies every minute
+  flux create kustomization podinfo \
+    --source=podinfo \
+    --path="./kustomize" \
+    --interval=30m \
+    --retry-interval=1m
+
+  # Create a Kustomization resource that decrypts SOPS encrypted manifests
+  flux create kustomization secrets \
+    --source=secrets \
+    --path="./secrets" \
+    --interval=10m \
+    --decryption-provider=sops \
+    --decryption-secret=sops-gpg
+
+  # Create a Kustomization resource with a strategic merge patch and a JSON 6902 patch
+  flux create kustomization podinfo \
+    --source=podinfo \
+    --path="./kustomize" \
+    --interval=10m \
+    --patch=./patches/replicas.yaml \
+    --patch=./patches/remove-hpa.yaml \
+    --patch-target="HorizontalPodAutoscaler/podinfo"
+
+  # Validate a Kustomization with the API server without persisting it
+  flux create kustomization contour \
+    --source=contour \
+    --path="./deploy" \
+    --dry-run=server
 `,
 	RunE: createKsCmdRun,
 }
@@ -77,6 +114,7 @@ type kustomizationFlags struct {
 	source             flags.KustomizationSource
 	path               flags.SafeRelativePath
 	prune              bool
+	force              bool
 	dependsOn          []string
 	validation         string
 	healthCheck        []string
@@ -85,6 +123,9 @@ type kustomizationFlags struct {
 	decryptionProvider flags.DecryptionProvider
 	decryptionSecret   string
 	targetNamespace    string
+	retryInterval      time.Duration
+	patches            []string
+	patchTargets       []string
 }
 
 var kustomizationArgs = NewKustomizationFlags()
@@ -92,7 +133,10 @@ var kustomizationArgs = NewKustomizationFlags()
 func init() {
 	createKsCmd.Flags().Var(&kustomizationArgs.source, "source", kustomizationArgs.source.Description())
 	createKsCmd.Flags().Var(&kustomizationArgs.path, "path", "path to the directory containing a kustomization.yaml file")
-	createKsCmd.Flags().BoolVar(&kustomizationArgs.prune, "prune", false, "enable garbage collection")
+	createKsCmd.Flags().BoolVar(&kustomizationArgs.prune, "prune", true,
+		"enable garbage collection, with --prune=false the objects removed from the source are left in the cluster")
+	createKsCmd.Flags().BoolVar(&kustomizationArgs.force, "force", false,
+		"recreate the objects that can't be updated because of immutable fields changes")
 	createKsCmd.Flags().StringArrayVar(&kustomizationArgs.healthCheck, "health-check", nil, "workload to be included in the health assessment, in the format '<kind>/<name>.<namespace>'")
 	createKsCmd.Flags().DurationVar(&kustomizationArgs.healthTimeout, "health-check-timeout", 2*time.Minute, "timeout of health checking operations")
 	createKsCmd.Flags().StringVar(&kustomizationArgs.validation, "validation", "", "validate the manifests before applying them on the cluster, can be 'client' or 'server'")
@@ -101,6 +145,11 @@ func init() {
 	createKsCmd.Flags().Var(&kustomizationArgs.decryptionProvider, "decryption-provider", kustomizationArgs.decryptionProvider.Description())
 	createKsCmd.Flags().StringVar(&kustomizationArgs.decryptionSecret, "decryption-secret", "", "set the Kubernetes secret name that contains the OpenPGP private keys used for sops decryption")
 	createKsCmd.Flags().StringVar(&kustomizationArgs.targetNamespace, "target-namespace", "", "overrides the namespace of all Kustomization objects reconciled by this Kustomization")
+	createKsCmd.Flags().DurationVar(&kustomizationArgs.retryInterval, "retry-interval", 0, "the interval at which to retry a failed reconciliation, defaults to the interval")
+	createKsCmd.Flags().StringArrayVar(&kustomizationArgs.patches, "patch", nil,
+		"file containing a strategic merge patch, a list of JSON 6902 operations, or a JSON 6902 patch with its target, may be repeated")
+	createKsCmd.Flags().StringArrayVar(&kustomizationArgs.patchTargets, "patch-target", nil,
+		"target of a --patch file listing JSON 6902 operations, in the format '<kind>/<name>' or '<kind>/<name>.<namespace>', given in the same order as the patches")
 	createCmd.AddCommand(createKsCmd)
 }
 
@@ -122,6 +171,31 @@ func createKsCmdRun(cmd *cobra.Command, args []string) error {
 	if !strings.HasPrefix(kustomizationArgs.path.String(), "./") {
 		return fmt.Errorf("path must begin with ./")
 	}
+	if createArgs.interval <= 0 {
+		return fmt.Errorf("interval must be greater than zero")
+	}
+	if kustomizationArgs.retryInterval < 0 {
+		return fmt.Errorf("retry interval must not be negative")
+	}
+	if kustomizationArgs.decryptionProvider != "" && kustomizationArgs.decryptionSecret == "" {
+		return fmt.Errorf("decryption secret is required when a decryption provider is set")
+	}
+	if kustomizationArgs.decryptionSecret != "" && kustomizationArgs.decryptionProvider == "" {
+		return fmt.Errorf("decryption provider is required when a decryption secret is set")
+	}
+
+	if err := utils.ValidateDependsOn(kustomizationArgs.dependsOn); err != nil {
+		return err
+	}
+
+	if kustomizationArgs.force {
+		logger.Warningf("--force deletes and recreates the objects whose immutable fields change, which may cause downtime")
+	}
+
+	patchesStrategicMerge, patchesJSON6902, err := readKustomizationPatches(kustomizationArgs.patches, kustomizationArgs.patchTargets)
+	if err != nil {
+		return err
+	}
 
 	if !createArgs.export {
 		logger.Generatef("generating Kustomization")
@@ -145,13 +219,16 @@ func createKsCmdRun(cmd *cobra.Command, args []string) error {
 			},
 			Path:  filepath.ToSlash(kustomizationArgs.path.String()),
 			Prune: kustomizationArgs.prune,
+			Force: kustomizationArgs.force,
 			SourceRef: kustomizev1.CrossNamespaceSourceReference{
 				Kind: kustomizationArgs.source.Kind,
 				Name: kustomizationArgs.source.Name,
 			},
-			Suspend:         false,
-			Validation:      kustomizationArgs.validation,
-			TargetNamespace: kustomizationArgs.targetNamespace,
+			Suspend:               false,
+			Validation:            kustomizationArgs.validation,
+			TargetNamespace:       kustomizationArgs.targetNamespace,
+			PatchesStrategicMerge: patchesStrategicMerge,
+			PatchesJSON6902:       patchesJSON6902,
 		},
 	}
 
@@ -196,38 +273,48 @@ func createKsCmdRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// the controller falls back to the interval when the retry interval is not set
+	if kustomizationArgs.retryInterval > 0 {
+		kustomization.Spec.RetryInterval = &metav1.Duration{
+			Duration: kustomizationArgs.retryInterval,
+		}
+	}
+
 	if kustomizationArgs.saName != "" {
 		kustomization.Spec.ServiceAccountName = kustomizationArgs.saName
 	}
 
 	if kustomizationArgs.decryptionProvider != "" {
 		kustomization.Spec.Decryption = &kustomizev1.Decryption{
-			Provider: kustomizationArgs.decryptionProvider.String(),
-		}
-
-		if kustomizationArgs.decryptionSecret != "" {
-			kustomization.Spec.Decryption.SecretRef = &meta.LocalObjectReference{Name: kustomizationArgs.decryptionSecret}
+			Provider:  kustomizationArgs.decryptionProvider.String(),
+			SecretRef: &meta.LocalObjectReference{Name: kustomizationArgs.decryptionSecret},
 		}
 	}
 
 	if createArgs.export {
-		return exportKs(kustomization)
+		return printExport(os.Stdout, exportKs(&kustomization), "yaml")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
 
+	warnMissingDependencies(ctx, kubeClient, kustomization)
+
 	logger.Actionf("applying Kustomization")
 	namespacedName, err := upsertKustomization(ctx, kubeClient, &kustomization)
 	if err != nil {
 		return err
 	}
 
+	if serverDryRun() {
+		return printDryRun(exportKs(&kustomization))
+	}
+
 	logger.Waitingf("waiting for Kustomization reconciliation")
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isKustomizationReady(ctx, kubeClient, namespacedName, &kustomization)); err != nil {
@@ -239,6 +326,94 @@ func createKsCmdRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// warnMissingDependencies warns about the dependencies that don't exist
+// yet, the Kustomization is not applied until they are created.
+func warnMissingDependencies(ctx context.Context, kubeClient client.Client, kustomization kustomizev1.Kustomization) {
+	_, deps := kustomization.GetDependsOn()
+	for _, dep := range deps {
+		if dep.Namespace == "" {
+			dep.Namespace = kustomization.Namespace
+		}
+		var dependency kustomizev1.Kustomization
+		err := kubeClient.Get(ctx, types.NamespacedName{Namespace: dep.Namespace, Name: dep.Name}, &dependency)
+		if errors.IsNotFound(err) {
+			logger.Warningf("dependency %s not found, the Kustomization is not applied until it is created", dep)
+		}
+	}
+}
+
+// readKustomizationPatches reads the patch files, which contain either a
+// strategic merge patch, a list of JSON 6902 operations that are given
+// the next of the targets, or a JSON 6902 patch with its own target.
+func readKustomizationPatches(files, targets []string) ([]apiextensionsv1.JSON, []kustomize.JSON6902Patch, error) {
+	var strategicMerge []apiextensionsv1.JSON
+	var json6902 []kustomize.JSON6902Patch
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading patch failed: %w", err)
+		}
+		raw, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("patch '%s' is not valid YAML: %w", file, err)
+		}
+
+		var patch kustomize.JSON6902Patch
+		var fields map[string]interface{}
+		switch {
+		case json.Unmarshal(raw, &patch.Patch) == nil:
+			if len(targets) == 0 {
+				return nil, nil, fmt.Errorf("patch '%s' lists JSON 6902 operations, a --patch-target is required", file)
+			}
+			if patch.Target, err = parsePatchTarget(targets[0]); err != nil {
+				return nil, nil, err
+			}
+			targets = targets[1:]
+		case json.Unmarshal(raw, &fields) != nil:
+			return nil, nil, fmt.Errorf("patch '%s' must be an object or a list of JSON 6902 operations", file)
+		case fields["kind"] == nil && fields["patch"] != nil:
+			if err := yaml.UnmarshalStrict(data, &patch); err != nil {
+				return nil, nil, fmt.Errorf("invalid JSON 6902 patch '%s': %w", file, err)
+			}
+			if patch.Target.Kind == "" && patch.Target.Name == "" && patch.Target.LabelSelector == "" {
+				return nil, nil, fmt.Errorf("JSON 6902 patch '%s' has no target", file)
+			}
+		default:
+			if fields["apiVersion"] == nil || fields["kind"] == nil || fields["metadata"] == nil {
+				return nil, nil, fmt.Errorf("strategic merge patch '%s' must have an apiVersion, kind and metadata", file)
+			}
+			strategicMerge = append(strategicMerge, apiextensionsv1.JSON{Raw: raw})
+			continue
+		}
+
+		for _, op := range patch.Patch {
+			if op.Op == "" || op.Path == "" {
+				return nil, nil, fmt.Errorf("JSON 6902 patch '%s' has an operation without op or path", file)
+			}
+		}
+		json6902 = append(json6902, patch)
+	}
+
+	if len(targets) > 0 {
+		return nil, nil, fmt.Errorf("%d patch targets are not used by any JSON 6902 patch", len(targets))
+	}
+	return strategicMerge, json6902, nil
+}
+
+// parsePatchTarget parses a patch target in the format '<kind>/<name>'
+// or '<kind>/<name>.<namespace>'.
+func parsePatchTarget(target string) (kustomize.Selector, error) {
+	kindName := strings.Split(target, "/")
+	if len(kindName) != 2 || kindName[0] == "" || kindName[1] == "" {
+		return kustomize.Selector{}, fmt.Errorf("invalid patch target '%s' must be in the format 'kind/name' or 'kind/name.namespace'", target)
+	}
+	selector := kustomize.Selector{Kind: kindName[0], Name: kindName[1]}
+	if i := strings.LastIndex(kindName[1], "."); i >= 0 {
+		selector.Name, selector.Namespace = kindName[1][:i], kindName[1][i+1:]
+	}
+	return selector, nil
+}
+
 func upsertKustomization(ctx context.Context, kubeClient client.Client,
 	kustomization *kustomizev1.Kustomization) (types.NamespacedName, error) {
 	namespacedName := types.NamespacedName{
@@ -246,6 +421,14 @@ func upsertKustomization(ctx context.Context, kubeClient client.Client,
 		Name:      kustomization.GetName(),
 	}
 
+	if createArgs.serverSide {
+		if err := applyServerSide(ctx, kubeClient, kustomization); err != nil {
+			return namespacedName, err
+		}
+		logger.Successf("Kustomization applied")
+		return namespacedName, nil
+	}
+
 	var existing kustomizev1.Kustomization
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -260,13 +443,20 @@ func upsertKustomization(ctx context.Context, kubeClient client.Client,
 		return namespacedName, err
 	}
 
+	if equality.Semantic.DeepEqual(existing.Labels, kustomization.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec, kustomization.Spec) {
+		logger.Successf("Kustomization unchanged")
+		return namespacedName, nil
+	}
+
+	resourceVersion := existing.ResourceVersion
 	existing.Labels = kustomization.Labels
 	existing.Spec = kustomization.Spec
 	if err := kubeClient.Update(ctx, &existing); err != nil {
 		return namespacedName, err
 	}
 	kustomization = &existing
-	logger.Successf("Kustomization updated")
+	logUpdateResult("Kustomization", resourceVersion, &existing)
 	return namespacedName, nil
 }
 
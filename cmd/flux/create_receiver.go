@@ -19,11 +19,17 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,6 +37,7 @@ import (
 	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 
+	"github.com/fluxcd/flux2/internal/flags"
 	"github.com/fluxcd/flux2/internal/utils"
 )
 
@@ -46,24 +53,49 @@ var createReceiverCmd = &cobra.Command{
 	--secret-ref webhook-token \
 	--resource GitRepository/webapp \
 	--resource HelmRepository/webapp
+
+  # Create a Receiver and print the webhook URL using a custom address
+  flux create receiver gitlab-receiver \
+	--type gitlab \
+	--event "Push Hook" \
+	--secret-ref webhook-token \
+	--resource GitRepository/webapp \
+	--webhook-url-base https://flux-webhook.example.com
+
+  # Create a Receiver reconciling all the GitRepositories of the namespace
+  flux create receiver github-receiver \
+	--type github \
+	--event push \
+	--secret-ref webhook-token \
+	--resource "GitRepository/*"
 `,
 	RunE: createReceiverCmdRun,
 }
 
 type receiverFlags struct {
-	receiverType string
-	secretRef    string
-	events       []string
-	resources    []string
+	receiverType   flags.ReceiverType
+	secretRef      string
+	events         []string
+	resources      []string
+	webhookURLBase string
+	webhookService string
 }
 
 var receiverArgs receiverFlags
 
 func init() {
-	createReceiverCmd.Flags().StringVar(&receiverArgs.receiverType, "type", "", "")
-	createReceiverCmd.Flags().StringVar(&receiverArgs.secretRef, "secret-ref", "", "")
-	createReceiverCmd.Flags().StringArrayVar(&receiverArgs.events, "event", []string{}, "")
-	createReceiverCmd.Flags().StringArrayVar(&receiverArgs.resources, "resource", []string{}, "")
+	createReceiverCmd.Flags().Var(&receiverArgs.receiverType, "type", receiverArgs.receiverType.Description())
+	createReceiverCmd.Flags().StringVar(&receiverArgs.secretRef, "secret-ref", "",
+		"the name of the secret containing the token used to validate the webhook payloads")
+	createReceiverCmd.Flags().StringArrayVar(&receiverArgs.events, "event", []string{},
+		"the webhook event types that trigger a reconciliation, may be repeated")
+	createReceiverCmd.Flags().StringArrayVar(&receiverArgs.resources, "resource", []string{},
+		"the resources to reconcile in the format '<kind>/<name>', may be repeated, "+
+			"'<kind>/*' is expanded to the objects of that kind found in the namespace")
+	createReceiverCmd.Flags().StringVar(&receiverArgs.webhookURLBase, "webhook-url-base", "",
+		"the address the webhook receiver is exposed on, used to print the webhook URL, defaults to the address of the webhook receiver service")
+	createReceiverCmd.Flags().StringVar(&receiverArgs.webhookService, "webhook-service", "webhook-receiver",
+		"the name of the notification-controller service in the namespace used to discover the webhook address")
 	createCmd.AddCommand(createReceiverCmd)
 }
 
@@ -82,18 +114,45 @@ func createReceiverCmdRun(cmd *cobra.Command, args []string) error {
 	}
 
 	resources := []notificationv1.CrossNamespaceObjectReference{}
+	var wildcardKinds []string
 	for _, resource := range receiverArgs.resources {
 		kind, name := utils.ParseObjectKindName(resource)
-		if kind == "" {
-			return fmt.Errorf("invalid event source '%s', must be in format <kind>/<name>", resource)
+		if kind == "" || name == "" {
+			return fmt.Errorf("invalid resource '%s', must be in format <kind>/<name>", resource)
+		}
+		k, ok := utils.ContainsEqualFoldItemString(alertEventSourceKinds, kind)
+		if !ok {
+			return fmt.Errorf("invalid resource '%s', kind must be one of: %s",
+				resource, strings.Join(alertEventSourceKinds, ", "))
+		}
+		if name == "*" {
+			wildcardKinds = append(wildcardKinds, k)
+			continue
 		}
 
 		resources = append(resources, notificationv1.CrossNamespaceObjectReference{
-			Kind: kind,
+			Kind: k,
 			Name: name,
 		})
 	}
 
+	if len(wildcardKinds) > 0 {
+		kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+		if err != nil {
+			return err
+		}
+		for _, kind := range wildcardKinds {
+			refs, err := receiverKindResources(kubeClient, kind)
+			if err != nil {
+				return err
+			}
+			if len(refs) == 0 {
+				logger.Warningf("no %s objects found in %s namespace for '%s/*'", kind, rootArgs.namespace, kind)
+			}
+			resources = append(resources, refs...)
+		}
+	}
+
 	if len(resources) == 0 {
 		return fmt.Errorf("atleast one resource is required")
 	}
@@ -114,7 +173,7 @@ func createReceiverCmdRun(cmd *cobra.Command, args []string) error {
 			Labels:    sourceLabels,
 		},
 		Spec: notificationv1.ReceiverSpec{
-			Type:      receiverArgs.receiverType,
+			Type:      receiverArgs.receiverType.String(),
 			Events:    receiverArgs.events,
 			Resources: resources,
 			SecretRef: meta.LocalObjectReference{
@@ -125,13 +184,13 @@ func createReceiverCmdRun(cmd *cobra.Command, args []string) error {
 	}
 
 	if createArgs.export {
-		return exportReceiver(receiver)
+		return printExport(os.Stdout, exportReceiver(&receiver), "yaml")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
@@ -142,17 +201,94 @@ func createReceiverCmdRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if serverDryRun() {
+		return printDryRun(exportReceiver(&receiver))
+	}
+
 	logger.Waitingf("waiting for Receiver reconciliation")
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isReceiverReady(ctx, kubeClient, namespacedName, &receiver)); err != nil {
+		if err == wait.ErrWaitTimeout {
+			logger.Warningf("Receiver %s is not ready yet, run 'flux get receivers %s' later to get its webhook path", name, name)
+			return nil
+		}
 		return err
 	}
 	logger.Successf("Receiver %s is ready", name)
 
-	logger.Successf("generated webhook URL %s", receiver.Status.URL)
+	if receiver.Status.URL == "" {
+		logger.Warningf("Receiver %s has no webhook path yet, run 'flux get receivers %s' later to get it", name, name)
+		return nil
+	}
+	logger.Successf("generated webhook path %s", receiver.Status.URL)
+
+	base := receiverArgs.webhookURLBase
+	if base == "" {
+		base, err = webhookAddress(ctx, kubeClient, types.NamespacedName{
+			Namespace: rootArgs.namespace,
+			Name:      receiverArgs.webhookService,
+		})
+		if err != nil {
+			logger.Warningf("unable to discover the webhook address, set --webhook-url-base to print the webhook URL: %s", err.Error())
+			return nil
+		}
+	}
+	logger.Successf("webhook URL %s%s", strings.TrimSuffix(base, "/"), receiver.Status.URL)
 	return nil
 }
 
+// webhookAddress returns the external address of the given webhook receiver
+// service, or its in-cluster address if none has been assigned.
+func webhookAddress(ctx context.Context, kubeClient client.Client, namespacedName types.NamespacedName) (string, error) {
+	var svc corev1.Service
+	if err := kubeClient.Get(ctx, namespacedName, &svc); err != nil {
+		return "", err
+	}
+
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			return "http://" + ingress.Hostname, nil
+		}
+		if ingress.IP != "" {
+			return "http://" + ingress.IP, nil
+		}
+	}
+
+	logger.Warningf("service %s has no external address, the webhook URL is only reachable from inside the cluster", namespacedName.Name)
+	return fmt.Sprintf("http://%s.%s", svc.Name, svc.Namespace), nil
+}
+
+// receiverKindResources returns the references to the objects of the
+// kind in the namespace, for a '<kind>/*' resource. The Receiver API
+// references objects by name, so the wildcard is expanded when the
+// Receiver is created.
+func receiverKindResources(kubeClient client.Client, kind string) ([]notificationv1.CrossNamespaceObjectReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	var gvk schema.GroupVersionKind
+	for known := range utils.NewScheme().AllKnownTypes() {
+		if known.Kind == kind {
+			gvk = known
+			break
+		}
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(kind + "List"))
+	if err := kubeClient.List(ctx, list, client.InNamespace(rootArgs.namespace)); err != nil {
+		return nil, fmt.Errorf("listing %s objects failed: %w", kind, err)
+	}
+
+	var refs []notificationv1.CrossNamespaceObjectReference
+	for _, item := range list.Items {
+		refs = append(refs, notificationv1.CrossNamespaceObjectReference{
+			Kind: kind,
+			Name: item.GetName(),
+		})
+	}
+	return refs, nil
+}
+
 func upsertReceiver(ctx context.Context, kubeClient client.Client,
 	receiver *notificationv1.Receiver) (types.NamespacedName, error) {
 	namespacedName := types.NamespacedName{
@@ -160,6 +296,14 @@ func upsertReceiver(ctx context.Context, kubeClient client.Client,
 		Name:      receiver.GetName(),
 	}
 
+	if createArgs.serverSide {
+		if err := applyServerSide(ctx, kubeClient, receiver); err != nil {
+			return namespacedName, err
+		}
+		logger.Successf("Receiver applied")
+		return namespacedName, nil
+	}
+
 	var existing notificationv1.Receiver
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -174,13 +318,20 @@ func upsertReceiver(ctx context.Context, kubeClient client.Client,
 		return namespacedName, err
 	}
 
+	if equality.Semantic.DeepEqual(existing.Labels, receiver.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec, receiver.Spec) {
+		logger.Successf("Receiver unchanged")
+		return namespacedName, nil
+	}
+
+	resourceVersion := existing.ResourceVersion
 	existing.Labels = receiver.Labels
 	existing.Spec = receiver.Spec
 	if err := kubeClient.Update(ctx, &existing); err != nil {
 		return namespacedName, err
 	}
 	receiver = &existing
-	logger.Successf("Receiver updated")
+	logUpdateResult("Receiver", resourceVersion, &existing)
 	return namespacedName, nil
 }
 
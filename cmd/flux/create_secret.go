@@ -42,6 +42,10 @@ func upsertSecret(ctx context.Context, kubeClient client.Client, secret corev1.S
 		Name:      secret.GetName(),
 	}
 
+	if createArgs.serverSide {
+		return applyServerSide(ctx, kubeClient, &secret)
+	}
+
 	var existing corev1.Secret
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -20,14 +20,16 @@ import (
 	"context"
 	"crypto/elliptic"
 	"fmt"
+	"io"
 	"net/url"
 
+	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
 
 	"github.com/fluxcd/flux2/internal/flags"
-	"github.com/fluxcd/flux2/internal/utils"
 	"github.com/fluxcd/flux2/pkg/manifestgen/sourcesecret"
 )
 
@@ -36,7 +38,9 @@ var createSecretGitCmd = &cobra.Command{
 	Short: "Create or update a Kubernetes secret for Git authentication",
 	Long: `
 The create secret git command generates a Kubernetes secret with Git credentials.
-For Git over SSH, the host and SSH keys are automatically generated and stored in the secret.
+The authentication type is detected from the scheme of the Git URL.
+For Git over SSH, the host and SSH keys are automatically generated and stored in the secret,
+unless a private key file and a known hosts file are provided.
 For Git over HTTP/S, the provided basic authentication credentials are stored in the secret.`,
 	Example: `  # Create a Git SSH authentication secret using an ECDSA P-521 curve public key
 
@@ -45,6 +49,17 @@ For Git over HTTP/S, the provided basic authentication credentials are stored in
     --ssh-key-algorithm=ecdsa \
     --ssh-ecdsa-curve=p521
 
+  # Create a Git SSH authentication secret from an existing private key
+  flux create secret git podinfo-auth \
+    --url=ssh://git@github.com/stefanprodan/podinfo \
+    --private-key-file=./id_rsa \
+    --known-hosts-file=./known_hosts
+
+  # Create a Git SSH authentication secret after checking the host key fingerprints
+  flux create secret git podinfo-auth \
+    --url=ssh://git@github.com/stefanprodan/podinfo \
+    --fetch-host-keys
+
   # Create a secret for a Git repository using basic authentication
   flux create secret git podinfo-auth \
     --url=https://github.com/stefanprodan/podinfo \
@@ -71,13 +86,17 @@ For Git over HTTP/S, the provided basic authentication credentials are stored in
 }
 
 type secretGitFlags struct {
-	url          string
-	username     string
-	password     string
-	keyAlgorithm flags.PublicKeyAlgorithm
-	rsaBits      flags.RSAKeyBits
-	ecdsaCurve   flags.ECDSACurve
-	caFile       string
+	url             string
+	username        string
+	password        string
+	keyAlgorithm    flags.PublicKeyAlgorithm
+	rsaBits         flags.RSAKeyBits
+	ecdsaCurve      flags.ECDSACurve
+	caFile          string
+	privateKeyFile  string
+	knownHostsFile  string
+	fetchHostKeys   bool
+	confirmHostKeys bool
 }
 
 var secretGitArgs = NewSecretGitFlags()
@@ -90,6 +109,12 @@ func init() {
 	createSecretGitCmd.Flags().Var(&secretGitArgs.rsaBits, "ssh-rsa-bits", secretGitArgs.rsaBits.Description())
 	createSecretGitCmd.Flags().Var(&secretGitArgs.ecdsaCurve, "ssh-ecdsa-curve", secretGitArgs.ecdsaCurve.Description())
 	createSecretGitCmd.Flags().StringVar(&secretGitArgs.caFile, "ca-file", "", "path to TLS CA file used for validating self-signed certificates")
+	createSecretGitCmd.Flags().StringVar(&secretGitArgs.privateKeyFile, "private-key-file", "", "path to a private key file used for authenticating to the Git SSH server")
+	createSecretGitCmd.Flags().StringVar(&secretGitArgs.knownHostsFile, "known-hosts-file", "", "path to a known hosts file, instead of scanning the host key of the Git SSH server")
+	createSecretGitCmd.Flags().BoolVar(&secretGitArgs.fetchHostKeys, "fetch-host-keys", false,
+		"print the fingerprints of the scanned host keys of the Git SSH server, and ask to accept them before they are stored")
+	createSecretGitCmd.Flags().BoolVar(&secretGitArgs.confirmHostKeys, "confirm-host-keys", false,
+		"accept the host keys fetched with --fetch-host-keys without prompting")
 
 	createSecretCmd.AddCommand(createSecretGitCmd)
 }
@@ -116,6 +141,13 @@ func createSecretGitCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("git URL parse failed: %w", err)
 	}
 
+	if err := validateFetchHostKeys(u, secretGitArgs.fetchHostKeys, secretGitArgs.confirmHostKeys); err != nil {
+		return err
+	}
+	if secretGitArgs.fetchHostKeys && secretGitArgs.knownHostsFile != "" {
+		return fmt.Errorf("--fetch-host-keys can't be used with --known-hosts-file")
+	}
+
 	labels, err := parseLabels()
 	if err != nil {
 		return err
@@ -129,14 +161,27 @@ func createSecretGitCmdRun(cmd *cobra.Command, args []string) error {
 	}
 	switch u.Scheme {
 	case "ssh":
+		if secretGitArgs.username != "" || secretGitArgs.password != "" {
+			return fmt.Errorf("for Git over SSH the username and password are not supported, use a private key instead")
+		}
 		opts.SSHHostname = u.Host
+		opts.PrivateKeyPath = secretGitArgs.privateKeyFile
+		opts.KnownHostsPath = secretGitArgs.knownHostsFile
 		opts.PrivateKeyAlgorithm = sourcesecret.PrivateKeyAlgorithm(secretGitArgs.keyAlgorithm)
 		opts.RSAKeyBits = int(secretGitArgs.rsaBits)
 		opts.ECDSACurve = secretGitArgs.ecdsaCurve.Curve
+		if secretGitArgs.fetchHostKeys {
+			if opts.KnownHosts, err = fetchHostKeys(u.Host, secretGitArgs.confirmHostKeys); err != nil {
+				return err
+			}
+		}
 	case "http", "https":
 		if secretGitArgs.username == "" || secretGitArgs.password == "" {
 			return fmt.Errorf("for Git over HTTP/S the username and password are required")
 		}
+		if secretGitArgs.privateKeyFile != "" || secretGitArgs.knownHostsFile != "" {
+			return fmt.Errorf("for Git over HTTP/S the private key and known hosts files are not supported")
+		}
 		opts.Username = secretGitArgs.username
 		opts.Password = secretGitArgs.password
 		opts.CAFilePath = secretGitArgs.caFile
@@ -165,7 +210,7 @@ func createSecretGitCmdRun(cmd *cobra.Command, args []string) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
@@ -176,3 +221,50 @@ func createSecretGitCmdRun(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// validateFetchHostKeys checks that the host keys are fetched for an
+// SSH URL, and confirmed only when fetched.
+func validateFetchHostKeys(u *url.URL, fetch, confirm bool) error {
+	if confirm && !fetch {
+		return fmt.Errorf("--confirm-host-keys requires --fetch-host-keys")
+	}
+	if fetch && u.Scheme != "ssh" {
+		return fmt.Errorf("--fetch-host-keys requires an ssh URL")
+	}
+	return nil
+}
+
+// fetchHostKeys scans the host keys of the Git SSH server and prints
+// their fingerprints. Unless confirmed, the keys are trusted only once
+// accepted at the prompt, as the scan itself could be intercepted.
+func fetchHostKeys(host string, confirmed bool) ([]byte, error) {
+	knownHosts, err := sourcesecret.ScanHostKey(host)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := knownHosts
+	for len(rest) > 0 {
+		var key ssh.PublicKey
+		_, _, key, _, rest, err = ssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the host keys of %s: %w", host, err)
+		}
+		logger.Successf("host key of %s: %s %s", host, key.Type(), ssh.FingerprintSHA256(key))
+	}
+
+	if confirmed {
+		return knownHosts, nil
+	}
+	prompt := promptui.Prompt{
+		Label:     "Do you trust the host keys",
+		IsConfirm: true,
+	}
+	if _, err := prompt.Run(); err != nil {
+		return nil, fmt.Errorf("host keys not accepted, aborting")
+	}
+	return knownHosts, nil
+}
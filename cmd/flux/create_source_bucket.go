@@ -21,9 +21,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -34,7 +38,6 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 
 	"github.com/fluxcd/flux2/internal/flags"
-	"github.com/fluxcd/flux2/internal/utils"
 )
 
 var createSourceBucketCmd = &cobra.Command{
@@ -59,19 +62,37 @@ For Buckets with static authentication, the credentials are stored in a Kubernet
     --endpoint=s3.amazonaws.com \
 	--region=us-east-1 \
     --interval=10m
+
+  # Create a source from a Bucket using the credentials of an existing secret
+  flux create source bucket podinfo \
+	--bucket-name=podinfo \
+    --endpoint=minio.minio.svc.cluster.local:9000 \
+	--secret-ref=minio-credentials \
+    --interval=10m
+
+  # Create a source from the team-a directory of a shared Bucket, excluding the docs
+  flux create source bucket team-a \
+	--bucket-name=shared \
+    --endpoint=minio.minio.svc.cluster.local:9000 \
+	--secret-ref=minio-credentials \
+	--prefix=team-a \
+	--ignore-paths="/team-a/docs/" \
+    --interval=10m
 `,
 	RunE: createSourceBucketCmdRun,
 }
 
 type sourceBucketFlags struct {
-	name      string
-	provider  flags.SourceBucketProvider
-	endpoint  string
-	accessKey string
-	secretKey string
-	region    string
-	insecure  bool
-	secretRef string
+	name        string
+	provider    flags.SourceBucketProvider
+	endpoint    string
+	accessKey   string
+	secretKey   string
+	region      string
+	insecure    bool
+	secretRef   string
+	prefix      string
+	ignorePaths []string
 }
 
 var sourceBucketArgs = NewSourceBucketFlags()
@@ -85,6 +106,10 @@ func init() {
 	createSourceBucketCmd.Flags().StringVar(&sourceBucketArgs.region, "region", "", "the bucket region")
 	createSourceBucketCmd.Flags().BoolVar(&sourceBucketArgs.insecure, "insecure", false, "for when connecting to a non-TLS S3 HTTP endpoint")
 	createSourceBucketCmd.Flags().StringVar(&sourceBucketArgs.secretRef, "secret-ref", "", "the name of an existing secret containing credentials")
+	createSourceBucketCmd.Flags().StringVar(&sourceBucketArgs.prefix, "prefix", "",
+		"the object prefix within the bucket, e.g. team-a/apps, only the objects under the prefix are included in the artifact")
+	createSourceBucketCmd.Flags().StringArrayVar(&sourceBucketArgs.ignorePaths, "ignore-paths", nil,
+		"path to exclude from the artifact in the .sourceignore format (same as .gitignore), may be repeated")
 
 	createSourceCmd.AddCommand(createSourceBucketCmd)
 }
@@ -109,6 +134,10 @@ func createSourceBucketCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("endpoint is required")
 	}
 
+	if err := validateSourceBucketArgs(); err != nil {
+		return err
+	}
+
 	sourceLabels, err := parseLabels()
 	if err != nil {
 		return err
@@ -120,6 +149,11 @@ func createSourceBucketCmdRun(cmd *cobra.Command, args []string) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	ignore, err := sourceBucketIgnore()
+	if err != nil {
+		return err
+	}
+
 	bucket := &sourcev1.Bucket{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -135,55 +169,62 @@ func createSourceBucketCmdRun(cmd *cobra.Command, args []string) error {
 			Interval: metav1.Duration{
 				Duration: createArgs.interval,
 			},
+			Ignore: ignore,
 		},
 	}
-	if sourceHelmArgs.secretRef != "" {
+	if sourceBucketArgs.secretRef != "" {
 		bucket.Spec.SecretRef = &meta.LocalObjectReference{
 			Name: sourceBucketArgs.secretRef,
 		}
 	}
 
+	var secret *corev1.Secret
+	if sourceBucketArgs.accessKey != "" {
+		secret = &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "Secret",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("bucket-%s", name),
+				Namespace: rootArgs.namespace,
+				Labels:    sourceLabels,
+			},
+			StringData: map[string]string{
+				"accesskey": sourceBucketArgs.accessKey,
+				"secretkey": sourceBucketArgs.secretKey,
+			},
+		}
+		bucket.Spec.SecretRef = &meta.LocalObjectReference{
+			Name: secret.Name,
+		}
+	}
+
 	if createArgs.export {
-		return exportBucket(*bucket)
+		if secret != nil {
+			if err := printExport(os.Stdout, secret, "yaml"); err != nil {
+				return err
+			}
+		}
+		return printExport(os.Stdout, exportBucket(bucket), "yaml")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
 
 	logger.Generatef("generating Bucket source")
 
-	if sourceBucketArgs.secretRef == "" {
-		secretName := fmt.Sprintf("bucket-%s", name)
-
-		secret := corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      secretName,
-				Namespace: rootArgs.namespace,
-				Labels:    sourceLabels,
-			},
-			StringData: map[string]string{},
-		}
-
-		if sourceBucketArgs.accessKey != "" && sourceBucketArgs.secretKey != "" {
-			secret.StringData["accesskey"] = sourceBucketArgs.accessKey
-			secret.StringData["secretkey"] = sourceBucketArgs.secretKey
-		}
-
-		if len(secret.StringData) > 0 {
-			logger.Actionf("applying secret with the bucket credentials")
-			if err := upsertSecret(ctx, kubeClient, secret); err != nil {
-				return err
-			}
-			bucket.Spec.SecretRef = &meta.LocalObjectReference{
-				Name: secretName,
-			}
-			logger.Successf("authentication configured")
+	if secret != nil {
+		logger.Actionf("applying secret with the bucket credentials")
+		if err := upsertSecret(ctx, kubeClient, *secret); err != nil {
+			return err
 		}
+		logger.Successf("authentication configured")
 	}
 
 	logger.Actionf("applying Bucket source")
@@ -192,6 +233,10 @@ func createSourceBucketCmdRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if serverDryRun() {
+		return printDryRun(exportBucket(bucket))
+	}
+
 	logger.Waitingf("waiting for Bucket source reconciliation")
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isBucketReady(ctx, kubeClient, namespacedName, bucket)); err != nil {
@@ -206,6 +251,77 @@ func createSourceBucketCmdRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// awsRegionRegexp matches the AWS region names, e.g. us-east-1 or
+// us-gov-west-1.
+var awsRegionRegexp = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
+// validateSourceBucketArgs checks the credentials flags against the
+// bucket provider. The aws provider can authenticate with the IAM role
+// of the controller, so it doesn't require a secret.
+func validateSourceBucketArgs() error {
+	if (sourceBucketArgs.accessKey == "") != (sourceBucketArgs.secretKey == "") {
+		return fmt.Errorf("access-key and secret-key must be set together")
+	}
+	if sourceBucketArgs.secretRef != "" && sourceBucketArgs.accessKey != "" {
+		return fmt.Errorf("secret-ref can't be used together with access-key and secret-key")
+	}
+
+	switch sourceBucketArgs.provider.String() {
+	case sourcev1.GenericBucketProvider:
+		if sourceBucketArgs.secretRef == "" && sourceBucketArgs.accessKey == "" {
+			return fmt.Errorf("the %s provider requires secret-ref, or access-key and secret-key", sourcev1.GenericBucketProvider)
+		}
+	case sourcev1.AmazonBucketProvider:
+		if sourceBucketArgs.region == "" {
+			return fmt.Errorf("region is required for the %s provider", sourcev1.AmazonBucketProvider)
+		}
+		if !awsRegionRegexp.MatchString(sourceBucketArgs.region) {
+			return fmt.Errorf("invalid region '%s' for the %s provider, e.g. us-east-1", sourceBucketArgs.region, sourcev1.AmazonBucketProvider)
+		}
+	}
+
+	if prefix := sourceBucketArgs.prefix; prefix != "" {
+		if strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("prefix '%s' must not start with a slash", prefix)
+		}
+		if clean := path.Clean(prefix); clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("prefix '%s' must be a directory within the bucket", prefix)
+		}
+	}
+	return nil
+}
+
+// sourceBucketIgnore returns the ignore patterns of the --prefix and
+// --ignore-paths flags, or nil for the default patterns to be used.
+// The Bucket API has no prefix field, so the prefix is written as
+// patterns that exclude every object outside of it.
+func sourceBucketIgnore() (*string, error) {
+	var patterns []string
+	if sourceBucketArgs.prefix != "" {
+		prefix := path.Clean(sourceBucketArgs.prefix)
+		patterns = append(patterns, "/*")
+		// re-include each parent directory, as git ignore patterns can't
+		// include a path whose parent is excluded
+		parts := strings.Split(prefix, "/")
+		for i := range parts[:len(parts)-1] {
+			parent := strings.Join(parts[:i+1], "/")
+			patterns = append(patterns, fmt.Sprintf("!/%s/", parent), fmt.Sprintf("/%s/*", parent))
+		}
+		patterns = append(patterns, fmt.Sprintf("!/%s/", prefix))
+	}
+	for _, pattern := range sourceBucketArgs.ignorePaths {
+		if strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("ignore paths must not be empty")
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	ignore := strings.Join(patterns, "\n")
+	return &ignore, nil
+}
+
 func upsertBucket(ctx context.Context, kubeClient client.Client,
 	bucket *sourcev1.Bucket) (types.NamespacedName, error) {
 	namespacedName := types.NamespacedName{
@@ -213,6 +329,14 @@ func upsertBucket(ctx context.Context, kubeClient client.Client,
 		Name:      bucket.GetName(),
 	}
 
+	if createArgs.serverSide {
+		if err := applyServerSide(ctx, kubeClient, bucket); err != nil {
+			return namespacedName, err
+		}
+		logger.Successf("Bucket source applied")
+		return namespacedName, nil
+	}
+
 	var existing sourcev1.Bucket
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -227,12 +351,19 @@ func upsertBucket(ctx context.Context, kubeClient client.Client,
 		return namespacedName, err
 	}
 
+	if equality.Semantic.DeepEqual(existing.Labels, bucket.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec, bucket.Spec) {
+		logger.Successf("Bucket source unchanged")
+		return namespacedName, nil
+	}
+
+	resourceVersion := existing.ResourceVersion
 	existing.Labels = bucket.Labels
 	existing.Spec = bucket.Spec
 	if err := kubeClient.Update(ctx, &existing); err != nil {
 		return namespacedName, err
 	}
 	bucket = &existing
-	logger.Successf("Bucket source updated")
+	logUpdateResult("Bucket source", resourceVersion, &existing)
 	return namespacedName, nil
 }
@@ -19,16 +19,21 @@ package main
 import (
 	"context"
 	"crypto/elliptic"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"net/url"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/fluxcd/pkg/apis/meta"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,7 +43,6 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/fluxcd/flux2/internal/flags"
-	"github.com/fluxcd/flux2/internal/utils"
 	"github.com/fluxcd/flux2/pkg/manifestgen/sourcesecret"
 )
 
@@ -47,14 +51,25 @@ type sourceGitFlags struct {
 	branch            string
 	tag               string
 	semver            string
+	commit            string
 	username          string
 	password          string
 	caFile            string
+	clientCertFile    string
+	clientKeyFile     string
 	keyAlgorithm      flags.PublicKeyAlgorithm
 	keyRSABits        flags.RSAKeyBits
 	keyECDSACurve     flags.ECDSACurve
 	secretRef         string
 	gitImplementation flags.GitImplementation
+	ignorePaths       []string
+	ignoreFile        string
+	verifyProvider    flags.GitVerificationProvider
+	verifySecretRef   string
+	verifyMode        flags.GitVerificationMode
+	strict            bool
+	fetchHostKeys     bool
+	confirmHostKeys   bool
 }
 
 var createSourceGitCmd = &cobra.Command{
@@ -79,6 +94,12 @@ For private Git repositories, the basic authentication credentials are stored in
     --url=https://github.com/stefanprodan/podinfo \
     --tag-semver=">=3.2.0 <3.3.0"
 
+  # Create a source from a Git repository pinned to a commit of the master branch
+  flux create source git podinfo \
+    --url=https://github.com/stefanprodan/podinfo \
+    --branch=master \
+    --commit=0a1d6dd33fd4aa1e3c2d2f0e0c0e7ea3c5d1cbd2
+
   # Create a source from a Git repository using SSH authentication
   flux create source git podinfo \
     --url=ssh://git@github.com/stefanprodan/podinfo \
@@ -97,6 +118,34 @@ For private Git repositories, the basic authentication credentials are stored in
     --url=https://github.com/stefanprodan/podinfo \
     --username=username \
     --password=password
+
+  # Create a source from a Git server with a self-signed certificate
+  flux create source git podinfo \
+    --url=https://git.example.com/stefanprodan/podinfo \
+    --git-implementation=libgit2 \
+    --ca-file=./ca.crt
+
+  # Create a source from a Git server requiring mutual TLS authentication
+  flux create source git podinfo \
+    --url=https://git.example.com/stefanprodan/podinfo \
+    --git-implementation=libgit2 \
+    --ca-file=./ca.crt \
+    --client-cert-file=./client.crt \
+    --client-key-file=./client.key
+
+  # Create a source that excludes the docs and tests from the artifact
+  flux create source git podinfo \
+    --url=https://github.com/stefanprodan/podinfo \
+    --branch=master \
+    --ignore-paths="/docs/" \
+    --ignore-paths="/test/"
+
+  # Create a source that only accepts commits signed with the trusted OpenPGP keys
+  flux create source git podinfo \
+    --url=https://github.com/stefanprodan/podinfo \
+    --branch=master \
+    --verify-provider=gpg \
+    --verify-secret-ref=pgp-public-keys
 `,
 	RunE: createSourceGitCmdRun,
 }
@@ -108,6 +157,8 @@ func init() {
 	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.branch, "branch", "master", "git branch")
 	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.tag, "tag", "", "git tag")
 	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.semver, "tag-semver", "", "git tag semver range")
+	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.commit, "commit", "",
+		"the full SHA-1 of the git commit to pin the source to, the commit is looked up in --branch")
 	createSourceGitCmd.Flags().StringVarP(&sourceGitArgs.username, "username", "u", "", "basic authentication username")
 	createSourceGitCmd.Flags().StringVarP(&sourceGitArgs.password, "password", "p", "", "basic authentication password")
 	createSourceGitCmd.Flags().Var(&sourceGitArgs.keyAlgorithm, "ssh-key-algorithm", sourceGitArgs.keyAlgorithm.Description())
@@ -116,6 +167,25 @@ func init() {
 	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.secretRef, "secret-ref", "", "the name of an existing secret containing SSH or basic credentials")
 	createSourceGitCmd.Flags().Var(&sourceGitArgs.gitImplementation, "git-implementation", sourceGitArgs.gitImplementation.Description())
 	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.caFile, "ca-file", "", "path to TLS CA file used for validating self-signed certificates, requires libgit2")
+	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.clientCertFile, "client-cert-file", "",
+		"path to TLS client certificate file used for mutual TLS authentication, requires libgit2 and --client-key-file")
+	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.clientKeyFile, "client-key-file", "",
+		"path to TLS client private key file used for mutual TLS authentication, requires libgit2 and --client-cert-file")
+
+	createSourceGitCmd.Flags().StringArrayVar(&sourceGitArgs.ignorePaths, "ignore-paths", nil,
+		"path to exclude from the artifact in the .sourceignore format (same as .gitignore), may be repeated")
+	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.ignoreFile, "ignore-file", "",
+		"local file containing the paths to exclude from the artifact in the .sourceignore format, combined with --ignore-paths")
+	createSourceGitCmd.Flags().Var(&sourceGitArgs.verifyProvider, "verify-provider", sourceGitArgs.verifyProvider.Description())
+	createSourceGitCmd.Flags().StringVar(&sourceGitArgs.verifySecretRef, "verify-secret-ref", "",
+		"the name of an existing secret containing the public keys of the trusted Git authors, enables the commit signature verification")
+	createSourceGitCmd.Flags().Var(&sourceGitArgs.verifyMode, "verify-mode", sourceGitArgs.verifyMode.Description())
+	createSourceGitCmd.Flags().BoolVar(&sourceGitArgs.fetchHostKeys, "fetch-host-keys", false,
+		"print the fingerprints of the scanned host keys of the Git SSH server, and ask to accept them before they are stored")
+	createSourceGitCmd.Flags().BoolVar(&sourceGitArgs.confirmHostKeys, "confirm-host-keys", false,
+		"accept the host keys fetched with --fetch-host-keys without prompting")
+	createSourceGitCmd.Flags().BoolVar(&sourceGitArgs.strict, "strict", false,
+		"fail if the secret given with --secret-ref is missing or has no recognized credentials, instead of printing a warning")
 
 	createSourceCmd.AddCommand(createSourceGitCmd)
 }
@@ -125,6 +195,7 @@ func newSourceGitFlags() sourceGitFlags {
 		keyAlgorithm:  flags.PublicKeyAlgorithm(sourcesecret.RSAPrivateKeyAlgorithm),
 		keyRSABits:    2048,
 		keyECDSACurve: flags.ECDSACurve{Curve: elliptic.P384()},
+		verifyMode:    "head",
 	}
 }
 
@@ -138,6 +209,10 @@ func createSourceGitCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("url is required")
 	}
 
+	if err := validateSourceGitCommit(); err != nil {
+		return err
+	}
+
 	if sourceGitArgs.gitImplementation.String() != sourcev1.LibGit2Implementation && sourceGitArgs.caFile != "" {
 		return fmt.Errorf("specifing a CA file requires --git-implementation=%s", sourcev1.LibGit2Implementation)
 	}
@@ -156,6 +231,58 @@ func createSourceGitCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("git URL scheme '%s' not supported, can be: ssh, http and https", u.Scheme)
 	}
 
+	if err := validateFetchHostKeys(u, sourceGitArgs.fetchHostKeys, sourceGitArgs.confirmHostKeys); err != nil {
+		return err
+	}
+	if sourceGitArgs.fetchHostKeys && (sourceGitArgs.secretRef != "" || createArgs.export) {
+		return fmt.Errorf("--fetch-host-keys can't be used with --secret-ref or --export, as no secret is generated")
+	}
+
+	if (sourceGitArgs.clientCertFile == "") != (sourceGitArgs.clientKeyFile == "") {
+		return fmt.Errorf("--client-cert-file and --client-key-file must be given together")
+	}
+	if sourceGitArgs.clientCertFile != "" {
+		if sourceGitArgs.gitImplementation.String() != sourcev1.LibGit2Implementation {
+			return fmt.Errorf("specifing a client certificate requires --git-implementation=%s", sourcev1.LibGit2Implementation)
+		}
+		if u.Scheme != "https" {
+			return fmt.Errorf("specifing a client certificate requires an https URL")
+		}
+		if sourceGitArgs.secretRef != "" {
+			return fmt.Errorf("specifing a client certificate is not supported with --secret-ref")
+		}
+		if _, err := tls.LoadX509KeyPair(sourceGitArgs.clientCertFile, sourceGitArgs.clientKeyFile); err != nil {
+			return fmt.Errorf("client certificate '%s' and key '%s' don't match: %w",
+				sourceGitArgs.clientCertFile, sourceGitArgs.clientKeyFile, err)
+		}
+	}
+
+	if sourceGitArgs.caFile != "" {
+		if u.Scheme != "https" {
+			return fmt.Errorf("specifing a CA file requires an https URL")
+		}
+		if sourceGitArgs.secretRef != "" {
+			return fmt.Errorf("specifing a CA file is not supported with --secret-ref, add the CA to the secret with 'flux create secret git --ca-file'")
+		}
+		caFile, err := ioutil.ReadFile(sourceGitArgs.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file '%s': %w", sourceGitArgs.caFile, err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(caFile) {
+			return fmt.Errorf("failed to parse CA file '%s': no PEM encoded certificates found", sourceGitArgs.caFile)
+		}
+	}
+
+	verifying := cmd.Flags().Changed("verify-provider") || cmd.Flags().Changed("verify-mode")
+	if verifying && sourceGitArgs.verifySecretRef == "" {
+		return fmt.Errorf("--verify-secret-ref is required for the commit signature verification")
+	}
+
+	ignore, err := sourceGitIgnore()
+	if err != nil {
+		return err
+	}
+
 	sourceLabels, err := parseLabels()
 	if err != nil {
 		return err
@@ -173,6 +300,7 @@ func createSourceGitCmdRun(cmd *cobra.Command, args []string) error {
 				Duration: createArgs.interval,
 			},
 			Reference: &sourcev1.GitRepositoryRef{},
+			Ignore:    ignore,
 		},
 	}
 
@@ -186,6 +314,7 @@ func createSourceGitCmdRun(cmd *cobra.Command, args []string) error {
 		gitRepository.Spec.Reference.Tag = sourceGitArgs.tag
 	} else {
 		gitRepository.Spec.Reference.Branch = sourceGitArgs.branch
+		gitRepository.Spec.Reference.Commit = sourceGitArgs.commit
 	}
 
 	if sourceGitArgs.secretRef != "" {
@@ -194,18 +323,42 @@ func createSourceGitCmdRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if sourceGitArgs.verifySecretRef != "" {
+		gitRepository.Spec.Verification = &sourcev1.GitRepositoryVerification{
+			Mode: sourceGitArgs.verifyMode.String(),
+			SecretRef: meta.LocalObjectReference{
+				Name: sourceGitArgs.verifySecretRef,
+			},
+		}
+	}
+
 	if createArgs.export {
-		return exportGit(gitRepository)
+		switch {
+		case sourceGitArgs.clientCertFile != "":
+			logger.Warningf("the credentials are not exported, generate their secret with 'flux create secret tls --export'")
+		case sourceGitArgs.caFile != "" || sourceGitArgs.username != "":
+			logger.Warningf("the credentials are not exported, generate their secret with 'flux create secret git --export'")
+		}
+		return printExport(os.Stdout, exportGit(&gitRepository), "yaml")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
 
+	if sourceGitArgs.secretRef != "" {
+		if err := checkGitSecretRef(ctx, kubeClient, sourceGitArgs.secretRef, rootArgs.namespace); err != nil {
+			if sourceGitArgs.strict {
+				return err
+			}
+			logger.Warningf("%s, the GitRepository will fail to authenticate until this is fixed", err)
+		}
+	}
+
 	logger.Generatef("generating GitRepository source")
 	if sourceGitArgs.secretRef == "" {
 		secretOpts := sourcesecret.Options{
@@ -219,10 +372,17 @@ func createSourceGitCmdRun(cmd *cobra.Command, args []string) error {
 			secretOpts.PrivateKeyAlgorithm = sourcesecret.PrivateKeyAlgorithm(sourceGitArgs.keyAlgorithm)
 			secretOpts.RSAKeyBits = int(sourceGitArgs.keyRSABits)
 			secretOpts.ECDSACurve = sourceGitArgs.keyECDSACurve.Curve
+			if sourceGitArgs.fetchHostKeys {
+				if secretOpts.KnownHosts, err = fetchHostKeys(u.Host, sourceGitArgs.confirmHostKeys); err != nil {
+					return err
+				}
+			}
 		case "https":
 			secretOpts.Username = sourceGitArgs.username
 			secretOpts.Password = sourceGitArgs.password
 			secretOpts.CAFilePath = sourceGitArgs.caFile
+			secretOpts.CertFilePath = sourceGitArgs.clientCertFile
+			secretOpts.KeyFilePath = sourceGitArgs.clientKeyFile
 		}
 		secret, err := sourcesecret.Generate(secretOpts)
 		if err != nil {
@@ -263,6 +423,10 @@ func createSourceGitCmdRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if serverDryRun() {
+		return printDryRun(exportGit(&gitRepository))
+	}
+
 	logger.Waitingf("waiting for GitRepository source reconciliation")
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isGitRepositoryReady(ctx, kubeClient, namespacedName, &gitRepository)); err != nil {
@@ -277,6 +441,95 @@ func createSourceGitCmdRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// gitSecretKeys lists the sets of keys which make up valid credentials
+// in a GitRepository secret.
+var gitSecretKeys = [][]string{
+	{sourcesecret.UsernameSecretKey, sourcesecret.PasswordSecretKey},
+	{sourcesecret.PrivateKeySecretKey, sourcesecret.KnownHostsSecretKey},
+	{sourcesecret.CertFileSecretKey, sourcesecret.KeyFileSecretKey},
+	{sourcesecret.CAFileSecretKey},
+}
+
+// checkGitSecretRef returns an error if the secret referenced by a
+// GitRepository does not exist, or does not hold any of the recognized
+// credentials.
+func checkGitSecretRef(ctx context.Context, kubeClient client.Client, name, namespace string) error {
+	var secret corev1.Secret
+	err := kubeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("secret '%s' not found in %s namespace, create it with 'flux create secret git'", name, namespace)
+		}
+		return err
+	}
+
+	for _, keys := range gitSecretKeys {
+		found := true
+		for _, key := range keys {
+			if _, ok := secret.Data[key]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return nil
+		}
+	}
+
+	var expected []string
+	for _, keys := range gitSecretKeys {
+		expected = append(expected, strings.Join(keys, "/"))
+	}
+	return fmt.Errorf("secret '%s' has no recognized credentials, expected the keys %s",
+		name, strings.Join(expected, ", or "))
+}
+
+// gitCommitRegexp matches a full SHA-1, which the controller needs to
+// look the commit up.
+var gitCommitRegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// validateSourceGitCommit checks that the --commit flag is a full SHA-1,
+// and that it isn't combined with the tag references.
+func validateSourceGitCommit() error {
+	if sourceGitArgs.commit == "" {
+		return nil
+	}
+	if sourceGitArgs.tag != "" || sourceGitArgs.semver != "" {
+		return fmt.Errorf("--commit can't be used together with --tag or --tag-semver")
+	}
+	if !gitCommitRegexp.MatchString(sourceGitArgs.commit) {
+		return fmt.Errorf("invalid commit '%s', must be the full 40 hexadecimal characters SHA-1", sourceGitArgs.commit)
+	}
+	return nil
+}
+
+// sourceGitIgnore returns the ignore patterns of the --ignore-file and
+// --ignore-paths flags, or nil for the default patterns to be used.
+func sourceGitIgnore() (*string, error) {
+	var patterns []string
+	if sourceGitArgs.ignoreFile != "" {
+		data, err := ioutil.ReadFile(sourceGitArgs.ignoreFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ignore file '%s': %w", sourceGitArgs.ignoreFile, err)
+		}
+		if strings.TrimSpace(string(data)) == "" {
+			return nil, fmt.Errorf("ignore file '%s' is empty", sourceGitArgs.ignoreFile)
+		}
+		patterns = append(patterns, strings.TrimRight(string(data), "\n"))
+	}
+	for _, path := range sourceGitArgs.ignorePaths {
+		if strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("ignore paths must not be empty")
+		}
+		patterns = append(patterns, path)
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	ignore := strings.Join(patterns, "\n")
+	return &ignore, nil
+}
+
 func upsertGitRepository(ctx context.Context, kubeClient client.Client,
 	gitRepository *sourcev1.GitRepository) (types.NamespacedName, error) {
 	namespacedName := types.NamespacedName{
@@ -284,6 +537,14 @@ func upsertGitRepository(ctx context.Context, kubeClient client.Client,
 		Name:      gitRepository.GetName(),
 	}
 
+	if createArgs.serverSide {
+		if err := applyServerSide(ctx, kubeClient, gitRepository); err != nil {
+			return namespacedName, err
+		}
+		logger.Successf("GitRepository source applied")
+		return namespacedName, nil
+	}
+
 	var existing sourcev1.GitRepository
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -298,13 +559,20 @@ func upsertGitRepository(ctx context.Context, kubeClient client.Client,
 		return namespacedName, err
 	}
 
+	if equality.Semantic.DeepEqual(existing.Labels, gitRepository.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec, gitRepository.Spec) {
+		logger.Successf("GitRepository source unchanged")
+		return namespacedName, nil
+	}
+
+	resourceVersion := existing.ResourceVersion
 	existing.Labels = gitRepository.Labels
 	existing.Spec = gitRepository.Spec
 	if err := kubeClient.Update(ctx, &existing); err != nil {
 		return namespacedName, err
 	}
 	gitRepository = &existing
-	logger.Successf("GitRepository source updated")
+	logUpdateResult("GitRepository source", resourceVersion, &existing)
 	return namespacedName, nil
 }
 
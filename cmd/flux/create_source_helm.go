@@ -26,6 +26,7 @@ import (
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,7 +37,6 @@ import (
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 
-	"github.com/fluxcd/flux2/internal/utils"
 	"github.com/fluxcd/flux2/pkg/manifestgen/sourcesecret"
 )
 
@@ -45,7 +45,8 @@ var createSourceHelmCmd = &cobra.Command{
 	Short: "Create or update a HelmRepository source",
 	Long: `
 The create source helm command generates a HelmRepository resource and waits for it to fetch the index.
-For private Helm repositories, the basic authentication credentials are stored in a Kubernetes secret.`,
+For private Helm repositories, the basic authentication credentials and the TLS certificates are stored in a Kubernetes secret.
+The PEM encoded certificate files are validated before anything is applied on the cluster.`,
 	Example: `  # Create a source from a public Helm repository
   flux create source helm podinfo \
     --url=https://stefanprodan.github.io/podinfo \
@@ -112,9 +113,14 @@ func createSourceHelmCmdRun(cmd *cobra.Command, args []string) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	if _, err := url.Parse(sourceHelmArgs.url); err != nil {
+	u, err := url.Parse(sourceHelmArgs.url)
+	if err != nil {
 		return fmt.Errorf("url parse failed: %w", err)
 	}
+	// the HelmRepository API of this version has no type field for OCI registries
+	if u.Scheme == "oci" {
+		return fmt.Errorf("OCI Helm repositories are not supported by this version of source-controller, the url must be HTTP/S")
+	}
 
 	helmRepository := &sourcev1.HelmRepository{
 		ObjectMeta: metav1.ObjectMeta{
@@ -137,18 +143,19 @@ func createSourceHelmCmdRun(cmd *cobra.Command, args []string) error {
 	}
 
 	if createArgs.export {
-		return exportHelmRepository(*helmRepository)
+		return printExport(os.Stdout, exportHelmRepository(helmRepository), "yaml")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
 
 	logger.Generatef("generating HelmRepository source")
+	var createdSecret *corev1.Secret
 	if sourceHelmArgs.secretRef == "" {
 		secretName := fmt.Sprintf("helm-%s", name)
 		secretOpts := sourcesecret.Options{
@@ -170,6 +177,15 @@ func createSourceHelmCmdRun(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		if len(s.StringData) > 0 {
+			// the secret is removed if it's new and the source fails to apply
+			err := kubeClient.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, &corev1.Secret{})
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			if errors.IsNotFound(err) {
+				createdSecret = &s
+			}
+
 			logger.Actionf("applying secret with repository credentials")
 			if err := upsertSecret(ctx, kubeClient, s); err != nil {
 				return err
@@ -184,9 +200,19 @@ func createSourceHelmCmdRun(cmd *cobra.Command, args []string) error {
 	logger.Actionf("applying HelmRepository source")
 	namespacedName, err := upsertHelmRepository(ctx, kubeClient, helmRepository)
 	if err != nil {
+		if createdSecret != nil {
+			logger.Actionf("deleting secret %s", createdSecret.Name)
+			if err := kubeClient.Delete(ctx, createdSecret); err != nil {
+				logger.Failuref("failed to delete secret: %s", err.Error())
+			}
+		}
 		return err
 	}
 
+	if serverDryRun() {
+		return printDryRun(exportHelmRepository(helmRepository))
+	}
+
 	logger.Waitingf("waiting for HelmRepository source reconciliation")
 	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
 		isHelmRepositoryReady(ctx, kubeClient, namespacedName, helmRepository)); err != nil {
@@ -208,6 +234,14 @@ func upsertHelmRepository(ctx context.Context, kubeClient client.Client,
 		Name:      helmRepository.GetName(),
 	}
 
+	if createArgs.serverSide {
+		if err := applyServerSide(ctx, kubeClient, helmRepository); err != nil {
+			return namespacedName, err
+		}
+		logger.Successf("source applied")
+		return namespacedName, nil
+	}
+
 	var existing sourcev1.HelmRepository
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -222,13 +256,20 @@ func upsertHelmRepository(ctx context.Context, kubeClient client.Client,
 		return namespacedName, err
 	}
 
+	if equality.Semantic.DeepEqual(existing.Labels, helmRepository.Labels) &&
+		equality.Semantic.DeepEqual(existing.Spec, helmRepository.Spec) {
+		logger.Successf("source unchanged")
+		return namespacedName, nil
+	}
+
+	resourceVersion := existing.ResourceVersion
 	existing.Labels = helmRepository.Labels
 	existing.Spec = helmRepository.Spec
 	if err := kubeClient.Update(ctx, &existing); err != nil {
 		return namespacedName, err
 	}
 	helmRepository = &existing
-	logger.Successf("source updated")
+	logUpdateResult("source", resourceVersion, &existing)
 	return namespacedName, nil
 }
 
@@ -20,8 +20,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 
-	"github.com/fluxcd/flux2/internal/utils"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -32,6 +37,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
+
+	"github.com/fluxcd/flux2/internal/flags"
 )
 
 var createTenantCmd = &cobra.Command{
@@ -50,6 +57,20 @@ reconcilers scope to the tenant namespaces.`,
     --with-namespace=frontend \
     --with-namespace=backend \
 	--export > dev-team.yaml
+
+  # Create a tenant with a least-privilege role and labeled namespaces
+  flux create tenant dev-team \
+    --with-namespace=frontend \
+    --cluster-role=dev-team-reconciler \
+    --with-namespace-labels=istio-injection=enabled
+
+  # Create a tenant along with a GitRepository and a Kustomization that
+  # reconciles the tenant's repository with the tenant service account
+  flux create tenant dev-team \
+    --with-namespace=frontend \
+    --with-source=https://github.com/example/dev-team \
+    --source-branch=main \
+    --source-path=./deploy
 `,
 	RunE: createTenantCmdRun,
 }
@@ -59,15 +80,25 @@ const (
 )
 
 type tenantFlags struct {
-	namespaces  []string
-	clusterRole string
+	namespaces      []string
+	namespaceLabels []string
+	clusterRole     string
+	sourceURL       string
+	sourceBranch    string
+	sourcePath      flags.SafeRelativePath
 }
 
 var tenantArgs tenantFlags
 
 func init() {
 	createTenantCmd.Flags().StringSliceVar(&tenantArgs.namespaces, "with-namespace", nil, "namespace belonging to this tenant")
+	createTenantCmd.Flags().StringSliceVar(&tenantArgs.namespaceLabels, "with-namespace-labels", nil,
+		"set labels on the tenant namespaces only (can specify multiple labels with commas: label1=value1,label2=value2)")
 	createTenantCmd.Flags().StringVar(&tenantArgs.clusterRole, "cluster-role", "cluster-admin", "cluster role of the tenant role binding")
+	createTenantCmd.Flags().StringVar(&tenantArgs.sourceURL, "with-source", "",
+		"git address of the tenant repository, for which a GitRepository and a Kustomization impersonating the tenant service account are generated in the first tenant namespace")
+	createTenantCmd.Flags().StringVar(&tenantArgs.sourceBranch, "source-branch", "master", "git branch of the tenant repository")
+	createTenantCmd.Flags().Var(&tenantArgs.sourcePath, "source-path", "path to the directory containing the tenant manifests, defaults to the repository root")
 	createCmd.AddCommand(createTenantCmd)
 }
 
@@ -88,6 +119,15 @@ func createTenantCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("with-namespace is required")
 	}
 
+	namespaceLabels, err := parseLabelPairs(tenantArgs.namespaceLabels)
+	if err != nil {
+		return err
+	}
+
+	if tenantArgs.sourceURL == "" && (cmd.Flags().Changed("source-branch") || cmd.Flags().Changed("source-path")) {
+		return fmt.Errorf("--source-branch and --source-path require --with-source")
+	}
+
 	var namespaces []corev1.Namespace
 	var accounts []corev1.ServiceAccount
 	var roleBindings []rbacv1.RoleBinding
@@ -104,10 +144,18 @@ func createTenantCmdRun(cmd *cobra.Command, args []string) error {
 
 		objLabels[tenantLabel] = tenant
 
+		nsLabels := make(map[string]string)
+		for k, v := range objLabels {
+			nsLabels[k] = v
+		}
+		for k, v := range namespaceLabels {
+			nsLabels[k] = v
+		}
+
 		namespace := corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:   ns,
-				Labels: objLabels,
+				Labels: nsLabels,
 			},
 		}
 		namespaces = append(namespaces, namespace)
@@ -149,19 +197,34 @@ func createTenantCmdRun(cmd *cobra.Command, args []string) error {
 		roleBindings = append(roleBindings, roleBinding)
 	}
 
+	var gitRepository *sourcev1.GitRepository
+	var kustomization *kustomizev1.Kustomization
+	if tenantArgs.sourceURL != "" {
+		gitRepository, kustomization, err = tenantSource(tenant, tenantArgs.namespaces[0])
+		if err != nil {
+			return err
+		}
+	}
+
 	if createArgs.export {
 		for i, _ := range tenantArgs.namespaces {
 			if err := exportTenant(namespaces[i], accounts[i], roleBindings[i]); err != nil {
 				return err
 			}
 		}
+		if gitRepository != nil {
+			if err := printExport(os.Stdout, exportGit(gitRepository), "yaml"); err != nil {
+				return err
+			}
+			return printExport(os.Stdout, exportKs(kustomization), "yaml")
+		}
 		return nil
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	kubeClient, err := createKubeClient()
 	if err != nil {
 		return err
 	}
@@ -183,16 +246,93 @@ func createTenantCmdRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if gitRepository != nil {
+		logger.Actionf("applying GitRepository source %s", gitRepository.Name)
+		if _, err := upsertGitRepository(ctx, kubeClient, gitRepository); err != nil {
+			return err
+		}
+
+		logger.Actionf("applying Kustomization %s", kustomization.Name)
+		if _, err := upsertKustomization(ctx, kubeClient, kustomization); err != nil {
+			return err
+		}
+	}
+
 	logger.Successf("tenant setup completed")
 	return nil
 }
 
+// tenantSource returns the GitRepository of the tenant repository, and
+// the Kustomization which applies it with the tenant service account,
+// so that it is restricted to the permissions of the tenant.
+func tenantSource(tenant, namespace string) (*sourcev1.GitRepository, *kustomizev1.Kustomization, error) {
+	u, err := url.Parse(tenantArgs.sourceURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("git URL parse failed: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ssh" {
+		return nil, nil, fmt.Errorf("git URL scheme '%s' not supported, can be: ssh, http and https", u.Scheme)
+	}
+
+	objLabels, err := parseLabels()
+	if err != nil {
+		return nil, nil, err
+	}
+	objLabels[tenantLabel] = tenant
+
+	gitRepository := &sourcev1.GitRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenant,
+			Namespace: namespace,
+			Labels:    objLabels,
+		},
+		Spec: sourcev1.GitRepositorySpec{
+			URL: tenantArgs.sourceURL,
+			Interval: metav1.Duration{
+				Duration: createArgs.interval,
+			},
+			Reference: &sourcev1.GitRepositoryRef{
+				Branch: tenantArgs.sourceBranch,
+			},
+		},
+	}
+
+	path := tenantArgs.sourcePath.String()
+	if path == "" {
+		path = "./"
+	}
+	kustomization := &kustomizev1.Kustomization{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenant,
+			Namespace: namespace,
+			Labels:    objLabels,
+		},
+		Spec: kustomizev1.KustomizationSpec{
+			Interval: metav1.Duration{
+				Duration: createArgs.interval,
+			},
+			Path:  filepath.ToSlash(path),
+			Prune: true,
+			SourceRef: kustomizev1.CrossNamespaceSourceReference{
+				Kind: sourcev1.GitRepositoryKind,
+				Name: tenant,
+			},
+			ServiceAccountName: tenant,
+		},
+	}
+	return gitRepository, kustomization, nil
+}
+
 func upsertNamespace(ctx context.Context, kubeClient client.Client, namespace corev1.Namespace) error {
 	namespacedName := types.NamespacedName{
 		Namespace: namespace.GetNamespace(),
 		Name:      namespace.GetName(),
 	}
 
+	if createArgs.serverSide {
+		return applyServerSide(ctx, kubeClient, &namespace)
+	}
+
 	var existing corev1.Namespace
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -222,6 +362,10 @@ func upsertServiceAccount(ctx context.Context, kubeClient client.Client, account
 		Name:      account.GetName(),
 	}
 
+	if createArgs.serverSide {
+		return applyServerSide(ctx, kubeClient, &account)
+	}
+
 	var existing corev1.ServiceAccount
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
@@ -251,6 +395,10 @@ func upsertRoleBinding(ctx context.Context, kubeClient client.Client, roleBindin
 		Name:      roleBinding.GetName(),
 	}
 
+	if createArgs.serverSide {
+		return applyServerSide(ctx, kubeClient, &roleBinding)
+	}
+
 	var existing rbacv1.RoleBinding
 	err := kubeClient.Get(ctx, namespacedName, &existing)
 	if err != nil {
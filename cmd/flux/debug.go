@@ -0,0 +1,295 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Collect debugging information about Flux resources",
+	Long: `The debug sub-commands collect the state of a Flux resource and of the objects it depends on,
+to be attached to a bug report. The values of the Secrets are never collected.`,
+}
+
+type debugFlags struct {
+	outputFile    string
+	logLines      int
+	fluxNamespace string
+}
+
+var debugArgs debugFlags
+
+func init() {
+	debugCmd.PersistentFlags().StringVar(&debugArgs.outputFile, "output-file", "",
+		"write the collected information to a .tar.gz archive, instead of printing it as YAML to stdout")
+	debugCmd.PersistentFlags().IntVar(&debugArgs.logLines, "log-lines", 100,
+		"the number of the most recent controller log lines about the resource to collect")
+	debugCmd.PersistentFlags().StringVar(&debugArgs.fluxNamespace, "flux-namespace", rootArgs.defaults.Namespace,
+		"the namespace where the Flux controllers are running")
+
+	rootCmd.AddCommand(debugCmd)
+}
+
+// debugBundle is the information collected about a resource. The
+// Secrets are described by their keys only.
+type debugBundle struct {
+	Object  client.Object `json:"object"`
+	Source  client.Object `json:"source,omitempty"`
+	Secrets []debugSecret `json:"secrets,omitempty"`
+	Events  []debugEvent  `json:"events,omitempty"`
+	Logs    []string      `json:"logs,omitempty"`
+}
+
+type debugSecret struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Found     bool     `json:"found"`
+	Keys      []string `json:"keys,omitempty"`
+}
+
+type debugEvent struct {
+	Time    string `json:"time"`
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Object  string `json:"object"`
+	Message string `json:"message"`
+}
+
+// debugRef refers to an object of which the events are collected.
+type debugRef struct {
+	kind string
+	types.NamespacedName
+}
+
+// debugObject prepares an object to be collected, with its kind set
+// and without the managed fields.
+func debugObject(object client.Object, gvk schema.GroupVersionKind) client.Object {
+	object.GetObjectKind().SetGroupVersionKind(gvk)
+	object.SetManagedFields(nil)
+	return object
+}
+
+// debugSecrets describes the Secrets by the names of their keys, and
+// whether they exist.
+func debugSecrets(ctx context.Context, kubeClient client.Client, refs []types.NamespacedName) ([]debugSecret, error) {
+	var secrets []debugSecret
+	for _, ref := range refs {
+		result := debugSecret{Name: ref.Name, Namespace: ref.Namespace}
+		var secret corev1.Secret
+		if err := kubeClient.Get(ctx, ref, &secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+		} else {
+			result.Found = true
+			for key := range secret.Data {
+				result.Keys = append(result.Keys, key)
+			}
+			sort.Strings(result.Keys)
+		}
+		secrets = append(secrets, result)
+	}
+	return secrets, nil
+}
+
+// debugEvents returns the events of the given objects, sorted by time.
+func debugEvents(ctx context.Context, kubeClient client.Client, refs ...debugRef) ([]debugEvent, error) {
+	namespaces := make(map[string]bool)
+	for _, ref := range refs {
+		namespaces[ref.Namespace] = true
+	}
+
+	var events []corev1.Event
+	for namespace := range namespaces {
+		var list corev1.EventList
+		if err := kubeClient.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, e := range list.Items {
+			for _, ref := range refs {
+				o := e.InvolvedObject
+				if o.Kind == ref.kind && o.Name == ref.Name && o.Namespace == ref.Namespace {
+					events = append(events, e)
+					break
+				}
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+
+	var result []debugEvent
+	for _, e := range events {
+		result = append(result, debugEvent{
+			Time:    eventTime(e).Format(time.RFC3339),
+			Type:    e.Type,
+			Reason:  e.Reason,
+			Object:  fmt.Sprintf("%s/%s", e.InvolvedObject.Kind, e.InvolvedObject.Name),
+			Message: strings.TrimSpace(e.Message),
+		})
+	}
+	return result, nil
+}
+
+// debugControllerLogs returns the last --log-lines lines logged by the
+// controller about the given object.
+func debugControllerLogs(ctx context.Context, controller string, ref debugRef) ([]string, error) {
+	cfg, err := utils.KubeConfig(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := clientset.AppsV1().Deployments(debugArgs.fluxNamespace).Get(ctx, controller, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting the %s deployment failed: %w", controller, err)
+	}
+	pods, err := clientset.CoreV1().Pods(debugArgs.fluxNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: createLabelStringFromMap(deployment.Spec.Selector.MatchLabels),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := template.New("log").Parse(controllerLogTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, pod := range pods.Items {
+		stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{}).Stream(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting the logs of %s failed: %w", pod.Name, err)
+		}
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			var l ControllerLogEntry
+			if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+				continue
+			}
+			l.normalize(scanner.Bytes())
+			if l.Kind != ref.kind || l.Name != ref.Name || l.Namespace != ref.Namespace {
+				continue
+			}
+			var line strings.Builder
+			if err := t.Execute(&line, &l); err != nil {
+				continue
+			}
+			lines = append(lines, strings.TrimSpace(line.String()))
+		}
+		stream.Close()
+	}
+
+	if len(lines) > debugArgs.logLines {
+		lines = lines[len(lines)-debugArgs.logLines:]
+	}
+	return lines, nil
+}
+
+// writeDebugBundle prints the bundle as YAML, or writes it to the
+// --output-file archive with a file per section.
+func writeDebugBundle(bundle debugBundle) error {
+	if debugArgs.outputFile == "" {
+		data, err := yaml.Marshal(bundle)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	file, err := os.Create(debugArgs.outputFile)
+	if err != nil {
+		return fmt.Errorf("writing archive failed: %w", err)
+	}
+	defer file.Close()
+
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+	write := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	sections := []struct {
+		name  string
+		value interface{}
+	}{
+		{"object.yaml", bundle.Object},
+		{"source.yaml", bundle.Source},
+		{"secrets.yaml", bundle.Secrets},
+		{"events.yaml", bundle.Events},
+	}
+	for _, section := range sections {
+		data, err := yaml.Marshal(section.value)
+		if err != nil {
+			return err
+		}
+		if err := write(section.name, data); err != nil {
+			return fmt.Errorf("writing archive failed: %w", err)
+		}
+	}
+	if err := write("controller.log", []byte(strings.Join(bundle.Logs, "\n")+"\n")); err != nil {
+		return fmt.Errorf("writing archive failed: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("writing archive failed: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("writing archive failed: %w", err)
+	}
+	logger.Successf("debug information written to %s", debugArgs.outputFile)
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var debugKsCmd = &cobra.Command{
+	Use:               "kustomization [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&kustomizev1.KustomizationList{}),
+	Aliases:           []string{"ks"},
+	Short:             "Collect debugging information about a Kustomization",
+	Long: `The debug kustomization command collects the Kustomization, its source,
+the keys of the Secrets they refer to, their events, and the last kustomize-controller
+log lines about the Kustomization.`,
+	Example: `  # Print the debugging information of a Kustomization
+  flux debug kustomization podinfo
+
+  # Write the debugging information to an archive to attach to a bug report
+  flux debug kustomization podinfo --output-file=debug.tar.gz
+`,
+	RunE: debugKsCmdRun,
+}
+
+func init() {
+	debugCmd.AddCommand(debugKsCmd)
+}
+
+func debugKsCmdRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("Kustomization name is required")
+	}
+	if debugArgs.logLines < 0 {
+		return fmt.Errorf("log lines must not be negative")
+	}
+	namespacedName := types.NamespacedName{
+		Namespace: rootArgs.namespace,
+		Name:      args[0],
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	var kustomization kustomizev1.Kustomization
+	if err := kubeClient.Get(ctx, namespacedName, &kustomization); err != nil {
+		return err
+	}
+	bundle := debugBundle{
+		Object: debugObject(&kustomization, kustomizev1.GroupVersion.WithKind(kustomizev1.KustomizationKind)),
+	}
+	refs := []debugRef{{kind: kustomizev1.KustomizationKind, NamespacedName: namespacedName}}
+
+	secretRef := func(name string) types.NamespacedName {
+		return types.NamespacedName{Namespace: namespacedName.Namespace, Name: name}
+	}
+	var secrets []types.NamespacedName
+	if d := kustomization.Spec.Decryption; d != nil && d.SecretRef != nil {
+		secrets = append(secrets, secretRef(d.SecretRef.Name))
+	}
+	if k := kustomization.Spec.KubeConfig; k != nil {
+		secrets = append(secrets, secretRef(k.SecretRef.Name))
+	}
+	if p := kustomization.Spec.PostBuild; p != nil {
+		for _, s := range p.SubstituteFrom {
+			if s.Kind == "Secret" {
+				secrets = append(secrets, secretRef(s.Name))
+			}
+		}
+	}
+
+	sourceName := types.NamespacedName{
+		Namespace: kustomization.Spec.SourceRef.Namespace,
+		Name:      kustomization.Spec.SourceRef.Name,
+	}
+	if sourceName.Namespace == "" {
+		sourceName.Namespace = namespacedName.Namespace
+	}
+	sourceSecret := func(ref string) {
+		secrets = append(secrets, types.NamespacedName{Namespace: sourceName.Namespace, Name: ref})
+	}
+	var repository sourcev1.GitRepository
+	var bucket sourcev1.Bucket
+	var source client.Object
+	switch kustomization.Spec.SourceRef.Kind {
+	case sourcev1.GitRepositoryKind:
+		source = &repository
+	case sourcev1.BucketKind:
+		source = &bucket
+	}
+	if source != nil {
+		kind := kustomization.Spec.SourceRef.Kind
+		if err := kubeClient.Get(ctx, sourceName, source); err != nil {
+			logger.Warningf("getting the %s %s failed: %s", kind, sourceName, err)
+		} else {
+			bundle.Source = debugObject(source, sourcev1.GroupVersion.WithKind(kind))
+			refs = append(refs, debugRef{kind: kind, NamespacedName: sourceName})
+		}
+	}
+	if repository.Spec.SecretRef != nil {
+		sourceSecret(repository.Spec.SecretRef.Name)
+	}
+	if repository.Spec.Verification != nil {
+		sourceSecret(repository.Spec.Verification.SecretRef.Name)
+	}
+	if bucket.Spec.SecretRef != nil {
+		sourceSecret(bucket.Spec.SecretRef.Name)
+	}
+
+	if bundle.Secrets, err = debugSecrets(ctx, kubeClient, secrets); err != nil {
+		return err
+	}
+	if bundle.Events, err = debugEvents(ctx, kubeClient, refs...); err != nil {
+		return err
+	}
+	if debugArgs.logLines > 0 {
+		if bundle.Logs, err = debugControllerLogs(ctx, "kustomize-controller", refs[0]); err != nil {
+			logger.Warningf("collecting the controller logs failed: %s", err)
+		}
+	}
+	return writeDebugBundle(bundle)
+}
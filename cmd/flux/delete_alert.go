@@ -29,9 +29,10 @@ import (
 )
 
 var deleteAlertCmd = &cobra.Command{
-	Use:   "alert [name]",
-	Short: "Delete a Alert resource",
-	Long:  "The delete alert command removes the given Alert from the cluster.",
+	Use:               "alert [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.AlertList{}),
+	Short:             "Delete a Alert resource",
+	Long:              "The delete alert command removes the given Alert from the cluster.",
 	Example: `  # Delete an Alert and the Kubernetes resources created by it
   flux delete alert main
 `,
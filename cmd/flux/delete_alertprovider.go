@@ -29,9 +29,10 @@ import (
 )
 
 var deleteAlertProviderCmd = &cobra.Command{
-	Use:   "alert-provider [name]",
-	Short: "Delete a Provider resource",
-	Long:  "The delete alert-provider command removes the given Provider from the cluster.",
+	Use:               "alert-provider [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.ProviderList{}),
+	Short:             "Delete a Provider resource",
+	Long:              "The delete alert-provider command removes the given Provider from the cluster.",
 	Example: `  # Delete a Provider and the Kubernetes resources created by it
   flux delete alert-provider slack
 `,
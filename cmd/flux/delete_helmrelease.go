@@ -22,10 +22,11 @@ import (
 )
 
 var deleteHelmReleaseCmd = &cobra.Command{
-	Use:     "helmrelease [name]",
-	Aliases: []string{"hr"},
-	Short:   "Delete a HelmRelease resource",
-	Long:    "The delete helmrelease command removes the given HelmRelease from the cluster.",
+	Use:               "helmrelease [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&helmv2.HelmReleaseList{}),
+	Aliases:           []string{"hr"},
+	Short:             "Delete a HelmRelease resource",
+	Long:              "The delete helmrelease command removes the given HelmRelease from the cluster.",
 	Example: `  # Delete a Helm release and the Kubernetes resources created by it
   flux delete hr podinfo
 `,
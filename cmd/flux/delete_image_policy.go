@@ -23,9 +23,10 @@ import (
 )
 
 var deleteImagePolicyCmd = &cobra.Command{
-	Use:   "policy [name]",
-	Short: "Delete an ImagePolicy object",
-	Long:  "The delete image policy command deletes the given ImagePolicy from the cluster.",
+	Use:               "policy [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&imagev1.ImagePolicyList{}),
+	Short:             "Delete an ImagePolicy object",
+	Long:              "The delete image policy command deletes the given ImagePolicy from the cluster.",
 	Example: `  # Delete an image policy
   flux delete image policy alpine3.x
 `,
@@ -23,9 +23,10 @@ import (
 )
 
 var deleteImageRepositoryCmd = &cobra.Command{
-	Use:   "repository [name]",
-	Short: "Delete an ImageRepository object",
-	Long:  "The delete image repository command deletes the given ImageRepository from the cluster.",
+	Use:               "repository [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&imagev1.ImageRepositoryList{}),
+	Short:             "Delete an ImageRepository object",
+	Long:              "The delete image repository command deletes the given ImageRepository from the cluster.",
 	Example: `  # Delete an image repository
   flux delete image repository alpine
 `,
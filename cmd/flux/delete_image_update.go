@@ -23,9 +23,10 @@ import (
 )
 
 var deleteImageUpdateCmd = &cobra.Command{
-	Use:   "update [name]",
-	Short: "Delete an ImageUpdateAutomation object",
-	Long:  "The delete image update command deletes the given ImageUpdateAutomation from the cluster.",
+	Use:               "update [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&autov1.ImageUpdateAutomationList{}),
+	Short:             "Delete an ImageUpdateAutomation object",
+	Long:              "The delete image update command deletes the given ImageUpdateAutomation from the cluster.",
 	Example: `  # Delete an image update automation
   flux delete image update latest-images
 `,
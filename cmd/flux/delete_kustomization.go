@@ -22,10 +22,11 @@ import (
 )
 
 var deleteKsCmd = &cobra.Command{
-	Use:     "kustomization [name]",
-	Aliases: []string{"ks"},
-	Short:   "Delete a Kustomization resource",
-	Long:    "The delete kustomization command deletes the given Kustomization from the cluster.",
+	Use:               "kustomization [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&kustomizev1.KustomizationList{}),
+	Aliases:           []string{"ks"},
+	Short:             "Delete a Kustomization resource",
+	Long:              "The delete kustomization command deletes the given Kustomization from the cluster.",
 	Example: `  # Delete a kustomization and the Kubernetes resources created by it
   flux delete kustomization podinfo
 `,
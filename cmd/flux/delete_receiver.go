@@ -29,9 +29,10 @@ import (
 )
 
 var deleteReceiverCmd = &cobra.Command{
-	Use:   "receiver [name]",
-	Short: "Delete a Receiver resource",
-	Long:  "The delete receiver command removes the given Receiver from the cluster.",
+	Use:               "receiver [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.ReceiverList{}),
+	Short:             "Delete a Receiver resource",
+	Long:              "The delete receiver command removes the given Receiver from the cluster.",
 	Example: `  # Delete an Receiver and the Kubernetes resources created by it
   flux delete receiver main
 `,
@@ -22,9 +22,10 @@ import (
 )
 
 var deleteSourceBucketCmd = &cobra.Command{
-	Use:   "bucket [name]",
-	Short: "Delete a Bucket source",
-	Long:  "The delete source bucket command deletes the given Bucket from the cluster.",
+	Use:               "bucket [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.BucketList{}),
+	Short:             "Delete a Bucket source",
+	Long:              "The delete source bucket command deletes the given Bucket from the cluster.",
 	Example: `  # Delete a Bucket source
   flux delete source bucket podinfo
 `,
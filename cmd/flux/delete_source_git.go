@@ -22,9 +22,10 @@ import (
 )
 
 var deleteSourceGitCmd = &cobra.Command{
-	Use:   "git [name]",
-	Short: "Delete a GitRepository source",
-	Long:  "The delete source git command deletes the given GitRepository from the cluster.",
+	Use:               "git [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.GitRepositoryList{}),
+	Short:             "Delete a GitRepository source",
+	Long:              "The delete source git command deletes the given GitRepository from the cluster.",
 	Example: `  # Delete a Git repository
   flux delete source git podinfo
 `,
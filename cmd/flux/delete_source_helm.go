@@ -28,9 +28,10 @@ import (
 )
 
 var deleteSourceHelmCmd = &cobra.Command{
-	Use:   "helm [name]",
-	Short: "Delete a HelmRepository source",
-	Long:  "The delete source helm command deletes the given HelmRepository from the cluster.",
+	Use:               "helm [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.HelmRepositoryList{}),
+	Short:             "Delete a HelmRepository source",
+	Long:              "The delete source helm command deletes the given HelmRepository from the cluster.",
 	Example: `  # Delete a Helm repository
   flux delete source helm podinfo
 `,
@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fluxcd/flux2/internal/utils"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+)
+
+var diffKsCmd = &cobra.Command{
+	Use:               "kustomization [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&kustomizev1.KustomizationList{}),
+	Aliases:           []string{"ks"},
+	Short:             "Diff a Kustomization against the cluster state",
+	Long: `The diff kustomization command builds the manifests of a Kustomization from a local directory,
+performs a server-side dry-run apply of each object, and prints the differences with the live objects.
+The target namespace, patches and images of the Kustomization are applied on top of the directory,
+followed by the post-build variable substitutions, with the substituteFrom values read from the cluster.
+The directory defaults to the path of the Kustomization, relative to the current working directory,
+and it must contain a kustomization.yaml file.
+The command exits with status code 1 when differences are found, and with status code 2 when it fails.`,
+	Example: `  # Diff the Kustomization from a checkout of its source
+  flux diff kustomization my-app
+
+  # Diff the Kustomization against an uncommitted local directory
+  flux diff kustomization my-app --path=./deploy/overlays/staging
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := diffKsCmdRun(cmd, args); err != nil {
+			if _, ok := err.(*RequestError); ok {
+				return err
+			}
+			return &RequestError{StatusCode: 2, Err: err}
+		}
+		return nil
+	},
+}
+
+type diffKsFlags struct {
+	path string
+}
+
+var diffKsArgs diffKsFlags
+
+func init() {
+	diffKsCmd.Flags().StringVar(&diffKsArgs.path, "path", "",
+		"local directory to build the manifests from, overrides the path of the Kustomization")
+	diffCmd.AddCommand(diffKsCmd)
+}
+
+// diffFieldManager is the field manager used for the server-side
+// dry-run apply requests.
+const diffFieldManager = "flux-diff"
+
+func diffKsCmdRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("kustomization name is required")
+	}
+	name := args[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	namespacedName := types.NamespacedName{
+		Namespace: rootArgs.namespace,
+		Name:      name,
+	}
+	var kustomization kustomizev1.Kustomization
+	err = kubeClient.Get(ctx, namespacedName, &kustomization)
+	if err != nil {
+		return err
+	}
+
+	path := diffKsArgs.path
+	if path == "" {
+		path = kustomization.Spec.Path
+	}
+
+	logger.Actionf("building manifests from %s", path)
+	objects, err := buildKustomization(path, kustomization)
+	if err != nil {
+		return err
+	}
+
+	// the ConfigMaps and Secrets of the substitutions are read from the
+	// cluster, as kustomize-controller would
+	vars, err := postBuildVars(ctx, kubeClient, kustomization, nil)
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, object := range objects {
+		if kustomization.Spec.PostBuild != nil {
+			if object, err = substituteVariables(object, vars); err != nil {
+				return err
+			}
+		}
+
+		if err := setDefaultNamespace(kubeClient, object, kustomization); err != nil {
+			return err
+		}
+
+		diff, err := diffObject(ctx, kubeClient, object)
+		if err != nil {
+			return err
+		}
+		if diff != "" {
+			changed++
+			fmt.Print(diff)
+		}
+	}
+
+	if changed > 0 {
+		return &RequestError{
+			StatusCode: 1,
+			Err:        fmt.Errorf("%d of %d objects would be changed", changed, len(objects)),
+		}
+	}
+	logger.Successf("no changes found in %d objects", len(objects))
+	return nil
+}
+
+// setDefaultNamespace sets the namespace of namespaced objects to the
+// target namespace of the Kustomization, or to the namespace of the
+// Kustomization if the object does not have one.
+func setDefaultNamespace(kubeClient client.Client, object *unstructured.Unstructured, kustomization kustomizev1.Kustomization) error {
+	gvk := object.GroupVersionKind()
+	mapping, err := kubeClient.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("%s/%s: %w", gvk.Kind, object.GetName(), err)
+	}
+	if mapping.Scope.Name() != apimeta.RESTScopeNameNamespace {
+		return nil
+	}
+	switch {
+	case kustomization.Spec.TargetNamespace != "":
+		object.SetNamespace(kustomization.Spec.TargetNamespace)
+	case object.GetNamespace() == "":
+		object.SetNamespace(kustomization.Namespace)
+	}
+	return nil
+}
+
+// diffObject performs a server-side dry-run apply of the object and
+// returns the unified diff between the live and the merged object,
+// or an empty string if they are equal.
+func diffObject(ctx context.Context, kubeClient client.Client, object *unstructured.Unstructured) (string, error) {
+	ref := fmt.Sprintf("%s/%s", object.GetKind(), object.GetName())
+	if ns := object.GetNamespace(); ns != "" {
+		ref = fmt.Sprintf("%s/%s/%s", object.GetKind(), ns, object.GetName())
+	}
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(object.GroupVersionKind())
+	var liveYAML []byte
+	err := kubeClient.Get(ctx, client.ObjectKeyFromObject(object), live)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return "", fmt.Errorf("%s: %w", ref, err)
+	default:
+		if liveYAML, err = diffYAML(live); err != nil {
+			return "", err
+		}
+	}
+
+	merged := object.DeepCopy()
+	err = kubeClient.Patch(ctx, merged, client.Apply, client.DryRunAll,
+		client.ForceOwnership, client.FieldOwner(diffFieldManager))
+	if err != nil {
+		return "", fmt.Errorf("%s: dry-run apply failed: %w", ref, err)
+	}
+	mergedYAML, err := diffYAML(merged)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(liveYAML)),
+		B:        difflib.SplitLines(string(mergedYAML)),
+		FromFile: "live/" + ref,
+		ToFile:   "merged/" + ref,
+		Context:  3,
+	})
+}
+
+// diffYAML serialises the object without the fields that are only
+// noise in a diff.
+func diffYAML(object *unstructured.Unstructured) ([]byte, error) {
+	object = object.DeepCopy()
+	object.SetManagedFields(nil)
+	return yaml.Marshal(object.Object)
+}
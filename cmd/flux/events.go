@@ -0,0 +1,217 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events [kind/name]",
+	Short: "Display the Kubernetes events of Flux resources",
+	Long: `The events command lists the Kubernetes events of the Flux resources, sorted by time.
+When a name is given, only the events of that resource are listed, the kind can be omitted if the name is unique.`,
+	Example: `  # List the events of all Flux resources in the flux-system namespace
+  flux events
+
+  # List the events of a Kustomization
+  flux events kustomization/podinfo --namespace=apps
+
+  # Stream the events of the Flux resources in all namespaces
+  flux events --watch --all-namespaces
+`,
+	RunE: eventsCmdRun,
+}
+
+type eventsFlags struct {
+	watch         bool
+	allNamespaces bool
+}
+
+var eventsArgs eventsFlags
+
+func init() {
+	eventsCmd.Flags().BoolVarP(&eventsArgs.watch, "watch", "w", false,
+		"after listing the events, watch for new ones")
+	eventsCmd.Flags().BoolVarP(&eventsArgs.allNamespaces, "all-namespaces", "A", false,
+		"list the events across all namespaces")
+	rootCmd.AddCommand(eventsCmd)
+}
+
+// fluxKinds are the kinds of the resources reconciled by the Flux controllers.
+var fluxKinds = []string{
+	kustomizationType.kind,
+	helmReleaseType.kind,
+	gitRepositoryType.kind,
+	helmRepositoryType.kind,
+	helmChartType.kind,
+	bucketType.kind,
+	alertType.kind,
+	alertProviderType.kind,
+	receiverType.kind,
+	imageRepositoryType.kind,
+	imagePolicyType.kind,
+	imageUpdateAutomationType.kind,
+}
+
+func eventsCmdRun(cmd *cobra.Command, args []string) error {
+	var kind, name string
+	if len(args) > 0 {
+		kind, name = utils.ParseObjectKindName(args[0])
+		if kind != "" {
+			k, ok := utils.ContainsEqualFoldItemString(fluxKinds, kind)
+			if !ok {
+				return fmt.Errorf("unsupported kind '%s', must be one of: %s", kind, strings.Join(fluxKinds, ", "))
+			}
+			kind = k
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	namespace := rootArgs.namespace
+	if eventsArgs.allNamespaces {
+		namespace = ""
+	}
+
+	var list corev1.EventList
+	if err := kubeClient.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	matches := func(e corev1.Event) bool {
+		if name != "" && e.InvolvedObject.Name != name {
+			return false
+		}
+		if kind != "" {
+			return e.InvolvedObject.Kind == kind
+		}
+		return utils.ContainsItemString(fluxKinds, e.InvolvedObject.Kind)
+	}
+
+	var events []corev1.Event
+	for _, e := range list.Items {
+		if matches(e) {
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+
+	header := []string{"Last seen", "Type", "Reason", "Object", "Message"}
+	if eventsArgs.allNamespaces {
+		header = append(namespaceHeader, header...)
+	}
+	var rows [][]string
+	for _, e := range events {
+		rows = append(rows, eventRow(e))
+	}
+
+	if len(rows) == 0 && !eventsArgs.watch {
+		logger.Failuref("no events found in %s namespace", rootArgs.namespace)
+		return nil
+	}
+	utils.PrintTable(os.Stdout, header, rows)
+
+	if eventsArgs.watch {
+		return watchEvents(list.ResourceVersion, namespace, matches)
+	}
+	return nil
+}
+
+// watchEvents prints the matching events from the given resource
+// version onwards, until interrupted.
+func watchEvents(resourceVersion, namespace string, matches func(corev1.Event) bool) error {
+	cfg, err := utils.KubeConfig(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := clientset.CoreV1().Events(namespace).Watch(context.Background(), metav1.ListOptions{
+		ResourceVersion: resourceVersion,
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	for event := range watcher.ResultChan() {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			if e, ok := event.Object.(*corev1.Event); ok && matches(*e) {
+				// the rows are printed as the events arrive, so their columns are only
+				// tab separated, rather than padded to the widths of the table above
+				fmt.Println(strings.Join(eventRow(*e), "\t"))
+			}
+		case watch.Error:
+			return fmt.Errorf("watching events failed: %v", event.Object)
+		}
+	}
+	return nil
+}
+
+func eventRow(e corev1.Event) []string {
+	row := []string{
+		duration.HumanDuration(time.Since(eventTime(e))),
+		e.Type,
+		e.Reason,
+		fmt.Sprintf("%s/%s", strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name),
+		strings.TrimSpace(e.Message),
+	}
+	if eventsArgs.allNamespaces {
+		return append([]string{e.Namespace}, row...)
+	}
+	return row
+}
+
+// eventTime returns the last time the event was seen, falling back to
+// the event time for events that are not aggregated.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.FirstTimestamp.Time
+}
@@ -19,34 +19,94 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
+	"github.com/fluxcd/flux2/internal/flags"
 	"github.com/fluxcd/flux2/internal/utils"
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
-	Short: "Export resources in YAML format",
-	Long:  "The export sub-commands export resources in YAML format.",
+	Short: "Export resources in YAML or JSON format",
+	Long:  "The export sub-commands export resources in YAML or JSON format.",
 }
 
 type exportFlags struct {
-	all bool
+	all              bool
+	output           flags.ExportFormat
+	outputDir        string
+	labelSelector    string
+	allNamespaces    bool
+	keepAnnotations  bool
+	concurrency      int
+	withDependencies bool
 }
 
-var exportArgs exportFlags
+var exportArgs = NewExportFlags()
 
 func init() {
 	exportCmd.PersistentFlags().BoolVar(&exportArgs.all, "all", false, "select all resources")
+	exportCmd.PersistentFlags().VarP(&exportArgs.output, "output", "o", exportArgs.output.Description())
+	exportCmd.PersistentFlags().StringVarP(&exportArgs.labelSelector, "label-selector", "l", "",
+		"filter the resources selected with --all by label, supports '=', '==', '!=', 'in', 'notin' and '!key'")
+	exportCmd.PersistentFlags().StringVar(&exportArgs.outputDir, "output-dir", "",
+		"write each resource to its own file in the given directory, instead of printing to stdout")
+	exportCmd.PersistentFlags().BoolVarP(&exportArgs.allNamespaces, "all-namespaces", "A", false,
+		"select the resources in all namespaces, requires --all")
+	exportCmd.PersistentFlags().BoolVar(&exportArgs.keepAnnotations, "keep-annotations", false,
+		"keep the annotations set by kubectl and by the reconcile commands, which are removed by default")
+	exportCmd.PersistentFlags().IntVar(&exportArgs.concurrency, "concurrency", 8,
+		"the number of credential secrets fetched at once when exporting several sources with their credentials")
 
 	rootCmd.AddCommand(exportCmd)
 }
 
+func NewExportFlags() exportFlags {
+	return exportFlags{
+		output: flags.ExportFormat("yaml"),
+	}
+}
+
+// exportedAnnotations are the annotations set by kubectl and by the
+// reconcile commands, which are not part of the desired state.
+var exportedAnnotations = []string{
+	meta.ReconcileRequestAnnotation,
+	corev1.LastAppliedConfigAnnotation,
+}
+
+// exportAnnotations returns the annotations to export, without the
+// ones set by kubectl and the reconcile commands unless requested.
+func exportAnnotations(annotations map[string]string) map[string]string {
+	if exportArgs.keepAnnotations {
+		return annotations
+	}
+	result := make(map[string]string)
+	for k, v := range annotations {
+		if !utils.ContainsItemString(exportedAnnotations, k) {
+			result[k] = v
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
 // exportable represents a type that you can fetch from the Kubernetes
 // API, then tidy up for serialising.
 type exportable interface {
@@ -61,7 +121,23 @@ type exportableList interface {
 	exportItem(i int) interface{}
 }
 
+// exportableWithDependencies represents an exportable type that is
+// exported along with the objects it refers to. The objects already
+// exported are given to be skipped.
+type exportableWithDependencies interface {
+	exportable
+	dependencies(ctx context.Context, kubeClient client.Client, exported map[string]bool) ([]interface{}, error)
+}
+
+// exportableWithDependenciesList is the analogue to
+// exportableWithDependencies, but for lists.
+type exportableWithDependenciesList interface {
+	exportableList
+	dependenciesItem(ctx context.Context, kubeClient client.Client, i int, exported map[string]bool) ([]interface{}, error)
+}
+
 type exportCommand struct {
+	apiType
 	object exportable
 	list   exportableList
 }
@@ -71,6 +147,45 @@ func (export exportCommand) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("name is required")
 	}
 
+	if exportArgs.allNamespaces && !exportArgs.all {
+		return fmt.Errorf("--all-namespaces can only be used with --all")
+	}
+
+	if exportArgs.concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+
+	if exportSourceWithCred && exportSourceRedacted {
+		return fmt.Errorf("--with-credentials and --redacted are mutually exclusive")
+	}
+	withSecrets := exportSourceWithCred || exportSourceRedacted
+	if _, ok := export.object.(exportableWithDependencies); ok && withSecrets && !exportArgs.withDependencies {
+		return fmt.Errorf("--with-credentials and --redacted require --with-dependencies")
+	}
+
+	if exportArgs.outputDir != "" {
+		if fi, err := os.Stat(exportArgs.outputDir); err != nil || !fi.IsDir() {
+			return fmt.Errorf("output directory %s does not exist", exportArgs.outputDir)
+		}
+	}
+
+	namespace := rootArgs.namespace
+	if exportArgs.allNamespaces {
+		namespace = ""
+	}
+	listOpts := []client.ListOption{client.InNamespace(namespace)}
+	if exportArgs.labelSelector != "" {
+		if !exportArgs.all && args[0] != "-" {
+			logger.Warningf("ignoring --label-selector, as only the %s named %s is exported", export.kind, args[0])
+		} else {
+			selector, err := labelSelectorOption(exportArgs.labelSelector)
+			if err != nil {
+				return err
+			}
+			listOpts = append(listOpts, selector)
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
@@ -79,21 +194,111 @@ func (export exportCommand) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// each exported object is grouped with the Secret it refers to,
+	// given that both are to be written to the same file
+	var objects []client.Object
+	var groups [][]interface{}
+	// the objects exported as dependencies, by kind, namespace and name
+	exported := make(map[string]bool)
+
+	// exportItems adds the list items at the given indices to the groups
+	exportItems := func(indices []int) error {
+		items, err := apimeta.ExtractList(export.list.asClientList())
+		if err != nil {
+			return err
+		}
+		secrets := make([]interface{}, len(indices))
+		if list, ok := export.list.(exportableWithSecretList); ok && withSecrets {
+			if secrets, err = exportSecrets(ctx, kubeClient, list, indices); err != nil {
+				return err
+			}
+		}
+		for n, i := range indices {
+			group := []interface{}{export.list.exportItem(i)}
+			if secrets[n] != nil {
+				group = append(group, secrets[n])
+			}
+			if list, ok := export.list.(exportableWithDependenciesList); ok && exportArgs.withDependencies {
+				deps, err := list.dependenciesItem(ctx, kubeClient, i, exported)
+				if err != nil {
+					return err
+				}
+				group = append(group, deps...)
+			}
+			objects = append(objects, items[i].(client.Object))
+			groups = append(groups, group)
+		}
+		return nil
+	}
+
+	// errs collects the names that could not be found when reading
+	// them from stdin, so that they can be reported all at once
+	var errs []error
+
 	if exportArgs.all {
-		err = kubeClient.List(ctx, export.list.asClientList(), client.InNamespace(rootArgs.namespace))
+		err = kubeClient.List(ctx, export.list.asClientList(), listOpts...)
 		if err != nil {
 			return err
 		}
 
 		if export.list.len() == 0 {
-			logger.Failuref("no objects found in %s namespace", rootArgs.namespace)
+			if exportArgs.allNamespaces {
+				logger.Failuref("no %s objects found in any namespace", export.kind)
+			} else {
+				logger.Failuref("no %s objects found in %s namespace", export.kind, rootArgs.namespace)
+			}
 			return nil
 		}
 
+		items, err := apimeta.ExtractList(export.list.asClientList())
+		if err != nil {
+			return err
+		}
+		var indices []int
 		for i := 0; i < export.list.len(); i++ {
-			if err = printExport(export.list.exportItem(i)); err != nil {
-				return err
+			indices = append(indices, i)
+		}
+		// sort by namespace and name, for the exports to be comparable
+		sort.SliceStable(indices, func(i, j int) bool {
+			a, b := items[indices[i]].(client.Object), items[indices[j]].(client.Object)
+			if a.GetNamespace() != b.GetNamespace() {
+				return a.GetNamespace() < b.GetNamespace()
 			}
+			return a.GetName() < b.GetName()
+		})
+		if err := exportItems(indices); err != nil {
+			return err
+		}
+	} else if args[0] == "-" {
+		names, err := utils.ReadNames(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading names from stdin failed: %w", err)
+		}
+
+		err = kubeClient.List(ctx, export.list.asClientList(), listOpts...)
+		if err != nil {
+			return err
+		}
+		items, err := apimeta.ExtractList(export.list.asClientList())
+		if err != nil {
+			return err
+		}
+		index := make(map[string]int, len(items))
+		for i, item := range items {
+			index[item.(client.Object).GetName()] = i
+		}
+
+		var indices []int
+		for _, name := range names {
+			i, ok := index[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s %s not found in %s namespace", export.kind, name, rootArgs.namespace))
+				continue
+			}
+			indices = append(indices, i)
+		}
+		if err := exportItems(indices); err != nil {
+			return err
 		}
 	} else {
 		name := args[0]
@@ -105,21 +310,139 @@ func (export exportCommand) run(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		return printExport(export.object.export())
+		group := []interface{}{export.object.export()}
+		if object, ok := export.object.(exportableWithSecret); ok && withSecrets {
+			if secretRef := object.secret(); secretRef != nil {
+				secret, err := exportSecret(ctx, kubeClient, *secretRef, exportSourceRedacted)
+				if err != nil {
+					return err
+				}
+				group = append(group, secret)
+			}
+		}
+		if object, ok := export.object.(exportableWithDependencies); ok && exportArgs.withDependencies {
+			deps, err := object.dependencies(ctx, kubeClient, exported)
+			if err != nil {
+				return err
+			}
+			group = append(group, deps...)
+		}
+		objects = append(objects, export.object.asClientObject())
+		groups = append(groups, group)
+	}
+
+	format := exportArgs.output.String()
+	if exportArgs.outputDir != "" {
+		for i, group := range groups {
+			filename := fmt.Sprintf("%s-%s-%s.%s", objects[i].GetNamespace(), objects[i].GetName(),
+				strings.ToLower(export.kind), format)
+			if err := writeExportFile(filepath.Join(exportArgs.outputDir, filename), group, format); err != nil {
+				return err
+			}
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+
+	var exports []interface{}
+	for _, group := range groups {
+		exports = append(exports, group...)
+	}
+	if exportArgs.all || args[0] == "-" {
+		if err := printExports(os.Stdout, exports, format); err != nil {
+			return err
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+	for _, e := range exports {
+		if err := printExport(os.Stdout, e, format); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func printExport(export interface{}) error {
-	data, err := yaml.Marshal(export)
+func writeExportFile(filename string, exports []interface{}, format string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("writing file failed: %w", err)
+	}
+	defer file.Close()
+
+	if len(exports) == 1 {
+		err = printExport(file, exports[0], format)
+	} else {
+		err = printExports(file, exports, format)
+	}
 	if err != nil {
 		return err
 	}
-	fmt.Println("---")
-	fmt.Println(resourceToString(data))
+	return file.Sync()
+}
+
+// printExport writes a single object in the given format. YAML
+// documents are preceded by a document separator, while JSON objects
+// are written as standalone documents.
+func printExport(w io.Writer, export interface{}, format string) error {
+	data, err := marshalExport(export, format)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		var out bytes.Buffer
+		if err := json.Indent(&out, data, "", "  "); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, out.String())
+		return nil
+	}
+	fmt.Fprintln(w, "---")
+	fmt.Fprintln(w, string(data))
 	return nil
 }
 
+// printExports writes a list of objects in the given format. In JSON
+// the objects are wrapped in an array, so that the output remains a
+// single valid document.
+func printExports(w io.Writer, exports []interface{}, format string) error {
+	if format != "json" {
+		for _, export := range exports {
+			if err := printExport(w, export, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	items := []json.RawMessage{}
+	for _, export := range exports {
+		data, err := marshalExport(export, format)
+		if err != nil {
+			return err
+		}
+		items = append(items, data)
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// marshalExport serialises the object to YAML, tidies it up, and
+// converts the result to JSON if requested.
+func marshalExport(export interface{}, format string) ([]byte, error) {
+	data, err := yaml.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+	data = []byte(resourceToString(data))
+	if format == "json" {
+		return yaml.YAMLToJSON(data)
+	}
+	return data, nil
+}
+
 func resourceToString(data []byte) string {
 	data = bytes.Replace(data, []byte("  creationTimestamp: null\n"), []byte(""), 1)
 	data = bytes.Replace(data, []byte("status: {}\n"), []byte(""), 1)
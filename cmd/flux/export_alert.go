@@ -17,84 +17,36 @@ limitations under the License.
 package main
 
 import (
-	"context"
-	"fmt"
-
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/yaml"
 
-	"github.com/fluxcd/flux2/internal/utils"
 	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
 )
 
 var exportAlertCmd = &cobra.Command{
-	Use:   "alert [name]",
-	Short: "Export Alert resources in YAML format",
-	Long:  "The export alert command exports one or all Alert resources in YAML format.",
+	Use:               "alert [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.AlertList{}),
+	Short:             "Export Alert resources in YAML format",
+	Long:              "The export alert command exports one or all Alert resources in YAML format.",
 	Example: `  # Export all Alert resources
   flux export alert --all > alerts.yaml
 
   # Export a Alert
   flux export alert main > main.yaml
 `,
-	RunE: exportAlertCmdRun,
+	RunE: exportCommand{
+		apiType: alertType,
+		object:  alertAdapter{&notificationv1.Alert{}},
+		list:    alertListAdapter{&notificationv1.AlertList{}},
+	}.run,
 }
 
 func init() {
 	exportCmd.AddCommand(exportAlertCmd)
 }
 
-func exportAlertCmdRun(cmd *cobra.Command, args []string) error {
-	if !exportArgs.all && len(args) < 1 {
-		return fmt.Errorf("name is required")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
-
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
-	if err != nil {
-		return err
-	}
-
-	if exportArgs.all {
-		var list notificationv1.AlertList
-		err = kubeClient.List(ctx, &list, client.InNamespace(rootArgs.namespace))
-		if err != nil {
-			return err
-		}
-
-		if len(list.Items) == 0 {
-			logger.Failuref("no alerts found in %s namespace", rootArgs.namespace)
-			return nil
-		}
-
-		for _, alert := range list.Items {
-			if err := exportAlert(alert); err != nil {
-				return err
-			}
-		}
-	} else {
-		name := args[0]
-		namespacedName := types.NamespacedName{
-			Namespace: rootArgs.namespace,
-			Name:      name,
-		}
-		var alert notificationv1.Alert
-		err = kubeClient.Get(ctx, namespacedName, &alert)
-		if err != nil {
-			return err
-		}
-		return exportAlert(alert)
-	}
-	return nil
-}
-
-func exportAlert(alert notificationv1.Alert) error {
-	gvk := notificationv1.GroupVersion.WithKind("Alert")
+func exportAlert(alert *notificationv1.Alert) interface{} {
+	gvk := notificationv1.GroupVersion.WithKind(notificationv1.AlertKind)
 	export := notificationv1.Alert{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       gvk.Kind,
@@ -104,17 +56,17 @@ func exportAlert(alert notificationv1.Alert) error {
 			Name:        alert.Name,
 			Namespace:   alert.Namespace,
 			Labels:      alert.Labels,
-			Annotations: alert.Annotations,
+			Annotations: exportAnnotations(alert.Annotations),
 		},
 		Spec: alert.Spec,
 	}
+	return export
+}
 
-	data, err := yaml.Marshal(export)
-	if err != nil {
-		return err
-	}
+func (ex alertAdapter) export() interface{} {
+	return exportAlert(ex.Alert)
+}
 
-	fmt.Println("---")
-	fmt.Println(resourceToString(data))
-	return nil
+func (ex alertListAdapter) exportItem(i int) interface{} {
+	return exportAlert(&ex.AlertList.Items[i])
 }
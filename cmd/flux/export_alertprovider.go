@@ -17,84 +17,36 @@ limitations under the License.
 package main
 
 import (
-	"context"
-	"fmt"
-
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/yaml"
 
-	"github.com/fluxcd/flux2/internal/utils"
 	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
 )
 
 var exportAlertProviderCmd = &cobra.Command{
-	Use:   "alert-provider [name]",
-	Short: "Export Provider resources in YAML format",
-	Long:  "The export alert-provider command exports one or all Provider resources in YAML format.",
+	Use:               "alert-provider [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.ProviderList{}),
+	Short:             "Export Provider resources in YAML format",
+	Long:              "The export alert-provider command exports one or all Provider resources in YAML format.",
 	Example: `  # Export all Provider resources
   flux export alert-provider --all > alert-providers.yaml
 
   # Export a Provider
   flux export alert-provider slack > slack.yaml
 `,
-	RunE: exportAlertProviderCmdRun,
+	RunE: exportCommand{
+		apiType: alertProviderType,
+		object:  alertProviderAdapter{&notificationv1.Provider{}},
+		list:    alertProviderListAdapter{&notificationv1.ProviderList{}},
+	}.run,
 }
 
 func init() {
 	exportCmd.AddCommand(exportAlertProviderCmd)
 }
 
-func exportAlertProviderCmdRun(cmd *cobra.Command, args []string) error {
-	if !exportArgs.all && len(args) < 1 {
-		return fmt.Errorf("name is required")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
-
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
-	if err != nil {
-		return err
-	}
-
-	if exportArgs.all {
-		var list notificationv1.ProviderList
-		err = kubeClient.List(ctx, &list, client.InNamespace(rootArgs.namespace))
-		if err != nil {
-			return err
-		}
-
-		if len(list.Items) == 0 {
-			logger.Failuref("no alertproviders found in %s namespace", rootArgs.namespace)
-			return nil
-		}
-
-		for _, alertProvider := range list.Items {
-			if err := exportAlertProvider(alertProvider); err != nil {
-				return err
-			}
-		}
-	} else {
-		name := args[0]
-		namespacedName := types.NamespacedName{
-			Namespace: rootArgs.namespace,
-			Name:      name,
-		}
-		var alertProvider notificationv1.Provider
-		err = kubeClient.Get(ctx, namespacedName, &alertProvider)
-		if err != nil {
-			return err
-		}
-		return exportAlertProvider(alertProvider)
-	}
-	return nil
-}
-
-func exportAlertProvider(alertProvider notificationv1.Provider) error {
-	gvk := notificationv1.GroupVersion.WithKind("Provider")
+func exportAlertProvider(alertProvider *notificationv1.Provider) interface{} {
+	gvk := notificationv1.GroupVersion.WithKind(notificationv1.ProviderKind)
 	export := notificationv1.Provider{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       gvk.Kind,
@@ -104,17 +56,17 @@ func exportAlertProvider(alertProvider notificationv1.Provider) error {
 			Name:        alertProvider.Name,
 			Namespace:   alertProvider.Namespace,
 			Labels:      alertProvider.Labels,
-			Annotations: alertProvider.Annotations,
+			Annotations: exportAnnotations(alertProvider.Annotations),
 		},
 		Spec: alertProvider.Spec,
 	}
+	return export
+}
 
-	data, err := yaml.Marshal(export)
-	if err != nil {
-		return err
-	}
+func (ex alertProviderAdapter) export() interface{} {
+	return exportAlertProvider(ex.Provider)
+}
 
-	fmt.Println("---")
-	fmt.Println(resourceToString(data))
-	return nil
+func (ex alertProviderListAdapter) exportItem(i int) interface{} {
+	return exportAlertProvider(&ex.ProviderList.Items[i])
 }
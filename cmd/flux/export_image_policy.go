@@ -24,9 +24,10 @@ import (
 )
 
 var exportImagePolicyCmd = &cobra.Command{
-	Use:   "policy [name]",
-	Short: "Export ImagePolicy resources in YAML format",
-	Long:  "The export image policy command exports one or all ImagePolicy resources in YAML format.",
+	Use:               "policy [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&imagev1.ImagePolicyList{}),
+	Short:             "Export ImagePolicy resources in YAML format",
+	Long:              "The export image policy command exports one or all ImagePolicy resources in YAML format.",
 	Example: `  # Export all ImagePolicy resources
   flux export image policy --all > image-policies.yaml
 
@@ -34,8 +35,9 @@ var exportImagePolicyCmd = &cobra.Command{
   flux export image policy alpine1x > alpine1x.yaml
 `,
 	RunE: exportCommand{
-		object: imagePolicyAdapter{&imagev1.ImagePolicy{}},
-		list:   imagePolicyListAdapter{&imagev1.ImagePolicyList{}},
+		apiType: imagePolicyType,
+		object:  imagePolicyAdapter{&imagev1.ImagePolicy{}},
+		list:    imagePolicyListAdapter{&imagev1.ImagePolicyList{}},
 	}.run,
 }
 
@@ -56,7 +58,7 @@ func exportImagePolicy(item *imagev1.ImagePolicy) interface{} {
 			Name:        item.Name,
 			Namespace:   item.Namespace,
 			Labels:      item.Labels,
-			Annotations: item.Annotations,
+			Annotations: exportAnnotations(item.Annotations),
 		},
 		Spec: item.Spec,
 	}
@@ -24,9 +24,10 @@ import (
 )
 
 var exportImageRepositoryCmd = &cobra.Command{
-	Use:   "repository [name]",
-	Short: "Export ImageRepository resources in YAML format",
-	Long:  "The export image repository command exports one or all ImageRepository resources in YAML format.",
+	Use:               "repository [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&imagev1.ImageRepositoryList{}),
+	Short:             "Export ImageRepository resources in YAML format",
+	Long:              "The export image repository command exports one or all ImageRepository resources in YAML format.",
 	Example: `  # Export all ImageRepository resources
   flux export image repository --all > image-repositories.yaml
 
@@ -34,8 +35,9 @@ var exportImageRepositoryCmd = &cobra.Command{
   flux export image repository alpine > alpine.yaml
 `,
 	RunE: exportCommand{
-		object: imageRepositoryAdapter{&imagev1.ImageRepository{}},
-		list:   imageRepositoryListAdapter{&imagev1.ImageRepositoryList{}},
+		apiType: imageRepositoryType,
+		object:  imageRepositoryAdapter{&imagev1.ImageRepository{}},
+		list:    imageRepositoryListAdapter{&imagev1.ImageRepositoryList{}},
 	}.run,
 }
 
@@ -54,7 +56,7 @@ func exportImageRepository(repo *imagev1.ImageRepository) interface{} {
 			Name:        repo.Name,
 			Namespace:   repo.Namespace,
 			Labels:      repo.Labels,
-			Annotations: repo.Annotations,
+			Annotations: exportAnnotations(repo.Annotations),
 		},
 		Spec: repo.Spec,
 	}
@@ -24,9 +24,10 @@ import (
 )
 
 var exportImageUpdateCmd = &cobra.Command{
-	Use:   "update [name]",
-	Short: "Export ImageUpdateAutomation resources in YAML format",
-	Long:  "The export image update command exports one or all ImageUpdateAutomation resources in YAML format.",
+	Use:               "update [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&autov1.ImageUpdateAutomationList{}),
+	Short:             "Export ImageUpdateAutomation resources in YAML format",
+	Long:              "The export image update command exports one or all ImageUpdateAutomation resources in YAML format.",
 	Example: `  # Export all ImageUpdateAutomation resources
   flux export image update --all > updates.yaml
 
@@ -34,8 +35,9 @@ var exportImageUpdateCmd = &cobra.Command{
   flux export image update latest-images > latest.yaml
 `,
 	RunE: exportCommand{
-		object: imageUpdateAutomationAdapter{&autov1.ImageUpdateAutomation{}},
-		list:   imageUpdateAutomationListAdapter{&autov1.ImageUpdateAutomationList{}},
+		apiType: imageUpdateAutomationType,
+		object:  imageUpdateAutomationAdapter{&autov1.ImageUpdateAutomation{}},
+		list:    imageUpdateAutomationListAdapter{&autov1.ImageUpdateAutomationList{}},
 	}.run,
 }
 
@@ -56,7 +58,7 @@ func exportImageUpdate(item *autov1.ImageUpdateAutomation) interface{} {
 			Name:        item.Name,
 			Namespace:   item.Namespace,
 			Labels:      item.Labels,
-			Annotations: item.Annotations,
+			Annotations: exportAnnotations(item.Annotations),
 		},
 		Spec: item.Spec,
 	}
@@ -21,101 +21,202 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/yaml"
 
-	"github.com/fluxcd/flux2/internal/utils"
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 )
 
 var exportKsCmd = &cobra.Command{
-	Use:     "kustomization [name]",
-	Aliases: []string{"ks"},
-	Short:   "Export Kustomization resources in YAML format",
-	Long:    "The export kustomization command exports one or all Kustomization resources in YAML format.",
+	Use:               "kustomization [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&kustomizev1.KustomizationList{}),
+	Aliases:           []string{"ks"},
+	Short:             "Export Kustomization resources in YAML format",
+	Long: `The export kustomization command exports one or all Kustomization resources in YAML format.
+With --with-dependencies, the source of each Kustomization and the ConfigMaps it refers to for the variable substitution
+are exported along with it, each object being exported once. The Secrets of the sources, of the decryption,
+of the kubeconfig and of the variable substitution are exported with --with-credentials or --redacted.`,
 	Example: `  # Export all Kustomization resources
   flux export kustomization --all > kustomizations.yaml
 
   # Export a Kustomization
   flux export kustomization my-app > kustomization.yaml
+
+  # Export a Kustomization in JSON format
+  flux export kustomization my-app -o json > kustomization.json
+
+  # Export a Kustomization with its source and the ConfigMaps it refers to
+  flux export kustomization my-app --with-dependencies > my-app.yaml
+
+  # Export all Kustomizations with their sources, ConfigMaps and Secrets
+  flux export kustomization --all --with-dependencies --with-credentials > bundle.yaml
 `,
-	RunE: exportKsCmdRun,
+	RunE: exportCommand{
+		apiType: kustomizationType,
+		object:  kustomizationAdapter{&kustomizev1.Kustomization{}},
+		list:    kustomizationListAdapter{&kustomizev1.KustomizationList{}},
+	}.run,
 }
 
 func init() {
+	exportKsCmd.Flags().BoolVar(&exportArgs.withDependencies, "with-dependencies", false,
+		"export the source and the ConfigMaps referenced by the Kustomization along with it")
+	exportKsCmd.Flags().BoolVar(&exportSourceWithCred, "with-credentials", false,
+		"export the Secrets referenced by the Kustomization and its source, requires --with-dependencies")
+	exportKsCmd.Flags().BoolVar(&exportSourceRedacted, "redacted", false,
+		"export the referenced Secrets with their values replaced by a placeholder, requires --with-dependencies")
+
 	exportCmd.AddCommand(exportKsCmd)
 }
 
-func exportKsCmdRun(cmd *cobra.Command, args []string) error {
-	if !exportArgs.all && len(args) < 1 {
-		return fmt.Errorf("kustomization name is required")
+func exportKs(kustomization *kustomizev1.Kustomization) interface{} {
+	gvk := kustomizev1.GroupVersion.WithKind(kustomizev1.KustomizationKind)
+	export := kustomizev1.Kustomization{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       gvk.Kind,
+			APIVersion: gvk.GroupVersion().String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        kustomization.Name,
+			Namespace:   kustomization.Namespace,
+			Labels:      kustomization.Labels,
+			Annotations: exportAnnotations(kustomization.Annotations),
+		},
+		Spec: kustomization.Spec,
 	}
+	return export
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
+func (ex kustomizationAdapter) export() interface{} {
+	return exportKs(ex.Kustomization)
+}
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
-	if err != nil {
-		return err
-	}
+func (ex kustomizationListAdapter) exportItem(i int) interface{} {
+	return exportKs(&ex.KustomizationList.Items[i])
+}
+
+func (ex kustomizationAdapter) dependencies(ctx context.Context, kubeClient client.Client, exported map[string]bool) ([]interface{}, error) {
+	return exportKsDependencies(ctx, kubeClient, ex.Kustomization, exported)
+}
 
-	if exportArgs.all {
-		var list kustomizev1.KustomizationList
-		err = kubeClient.List(ctx, &list, client.InNamespace(rootArgs.namespace))
+func (ex kustomizationListAdapter) dependenciesItem(ctx context.Context, kubeClient client.Client, i int, exported map[string]bool) ([]interface{}, error) {
+	return exportKsDependencies(ctx, kubeClient, &ex.KustomizationList.Items[i], exported)
+}
+
+// exportKsDependencies exports the source of the Kustomization and the
+// ConfigMaps it refers to, and the Secrets when exporting credentials.
+// The objects already in exported are skipped, so that the sources
+// shared by several Kustomizations are exported once.
+func exportKsDependencies(ctx context.Context, kubeClient client.Client,
+	kustomization *kustomizev1.Kustomization, exported map[string]bool) ([]interface{}, error) {
+	withSecrets := exportSourceWithCred || exportSourceRedacted
+	var exports []interface{}
+	add := func(kind string, namespacedName types.NamespacedName, export func() (interface{}, error)) error {
+		key := fmt.Sprintf("%s/%s", kind, namespacedName)
+		if exported[key] {
+			return nil
+		}
+		e, err := export()
 		if err != nil {
 			return err
 		}
-
-		if len(list.Items) == 0 {
-			logger.Failuref("no kustomizations found in %s namespace", rootArgs.namespace)
+		exported[key] = true
+		exports = append(exports, e)
+		return nil
+	}
+	addSecret := func(name string) error {
+		if !withSecrets {
 			return nil
 		}
+		namespacedName := types.NamespacedName{Namespace: kustomization.Namespace, Name: name}
+		return add("Secret", namespacedName, func() (interface{}, error) {
+			return exportSecret(ctx, kubeClient, namespacedName, exportSourceRedacted)
+		})
+	}
+
+	ref := kustomization.Spec.SourceRef
+	sourceName := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if sourceName.Namespace == "" {
+		sourceName.Namespace = kustomization.Namespace
+	}
+	var source exportableWithSecret
+	switch ref.Kind {
+	case sourcev1.GitRepositoryKind:
+		source = gitRepositoryAdapter{&sourcev1.GitRepository{}}
+	case sourcev1.BucketKind:
+		source = bucketAdapter{&sourcev1.Bucket{}}
+	default:
+		return nil, fmt.Errorf("unsupported source kind '%s' of Kustomization %s", ref.Kind, kustomization.Name)
+	}
+	// the source is only fetched when not exported yet, its secret is
+	// then unknown and was exported along with it
+	err := add(ref.Kind, sourceName, func() (interface{}, error) {
+		if err := kubeClient.Get(ctx, sourceName, source.asClientObject()); err != nil {
+			return nil, fmt.Errorf("failed to retrieve %s %s of Kustomization %s: %w", ref.Kind, sourceName, kustomization.Name, err)
+		}
+		return source.export(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if secretRef := source.secret(); secretRef != nil && withSecrets {
+		err := add("Secret", *secretRef, func() (interface{}, error) {
+			return exportSecret(ctx, kubeClient, *secretRef, exportSourceRedacted)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		for _, kustomization := range list.Items {
-			if err := exportKs(kustomization); err != nil {
-				return err
-			}
+	if d := kustomization.Spec.Decryption; d != nil && d.SecretRef != nil {
+		if err := addSecret(d.SecretRef.Name); err != nil {
+			return nil, err
 		}
-	} else {
-		name := args[0]
-		namespacedName := types.NamespacedName{
-			Namespace: rootArgs.namespace,
-			Name:      name,
+	}
+	if k := kustomization.Spec.KubeConfig; k != nil {
+		if err := addSecret(k.SecretRef.Name); err != nil {
+			return nil, err
 		}
-		var kustomization kustomizev1.Kustomization
-		err = kubeClient.Get(ctx, namespacedName, &kustomization)
-		if err != nil {
-			return err
+	}
+	if kustomization.Spec.PostBuild != nil {
+		for _, sub := range kustomization.Spec.PostBuild.SubstituteFrom {
+			var err error
+			switch sub.Kind {
+			case "Secret":
+				err = addSecret(sub.Name)
+			case "ConfigMap":
+				namespacedName := types.NamespacedName{Namespace: kustomization.Namespace, Name: sub.Name}
+				err = add(sub.Kind, namespacedName, func() (interface{}, error) {
+					return exportConfigMap(ctx, kubeClient, namespacedName)
+				})
+			}
+			if err != nil {
+				return nil, err
+			}
 		}
-		return exportKs(kustomization)
 	}
-	return nil
+	return exports, nil
 }
 
-func exportKs(kustomization kustomizev1.Kustomization) error {
-	gvk := kustomizev1.GroupVersion.WithKind("Kustomization")
-	export := kustomizev1.Kustomization{
+func exportConfigMap(ctx context.Context, kubeClient client.Client, namespacedName types.NamespacedName) (interface{}, error) {
+	var configMap corev1.ConfigMap
+	if err := kubeClient.Get(ctx, namespacedName, &configMap); err != nil {
+		return nil, fmt.Errorf("failed to retrieve config map %s, error: %w", namespacedName.Name, err)
+	}
+	return corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
-			Kind:       gvk.Kind,
-			APIVersion: gvk.GroupVersion().String(),
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        kustomization.Name,
-			Namespace:   kustomization.Namespace,
-			Labels:      kustomization.Labels,
-			Annotations: kustomization.Annotations,
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+			Labels:    configMap.Labels,
 		},
-		Spec: kustomization.Spec,
-	}
-
-	data, err := yaml.Marshal(export)
-	if err != nil {
-		return err
-	}
-
-	fmt.Println("---")
-	fmt.Println(resourceToString(data))
-	return nil
+		Data:       configMap.Data,
+		BinaryData: configMap.BinaryData,
+	}, nil
 }
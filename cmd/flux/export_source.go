@@ -17,21 +17,116 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"fmt"
+	"sync"
+
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var exportSourceCmd = &cobra.Command{
 	Use:   "source",
 	Short: "Export sources",
-	Long:  "The export source sub-commands export sources in YAML format.",
+	Long:  "The export source sub-commands export sources in YAML or JSON format.",
 }
 
 var (
 	exportSourceWithCred bool
+	exportSourceRedacted bool
 )
 
+// redactedValue is the placeholder for the values of Secrets exported
+// with --redacted.
+const redactedValue = "<redacted>"
+
 func init() {
 	exportSourceCmd.PersistentFlags().BoolVar(&exportSourceWithCred, "with-credentials", false, "include credential secrets")
+	exportSourceCmd.PersistentFlags().BoolVar(&exportSourceRedacted, "redacted", false,
+		"include credential secrets with their values replaced by a placeholder, cannot be combined with --with-credentials")
 
 	exportCmd.AddCommand(exportSourceCmd)
 }
+
+// exportableWithSecret represents an exportable type that may refer
+// to a Secret holding its credentials.
+type exportableWithSecret interface {
+	exportable
+	secret() *types.NamespacedName
+}
+
+// exportableWithSecretList is the analogue to exportableWithSecret,
+// but for lists.
+type exportableWithSecretList interface {
+	exportableList
+	secretItem(i int) *types.NamespacedName
+}
+
+func exportSecret(ctx context.Context, kubeClient client.Client, namespacedName types.NamespacedName, redacted bool) (interface{}, error) {
+	var cred corev1.Secret
+	err := kubeClient.Get(ctx, namespacedName, &cred)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve secret %s, error: %w", namespacedName.Name, err)
+	}
+
+	exported := corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+		},
+		Data: cred.Data,
+		Type: cred.Type,
+	}
+
+	// the placeholders are written to stringData, so that they remain
+	// readable instead of being base64 encoded
+	if redacted {
+		exported.Data = nil
+		exported.StringData = make(map[string]string, len(cred.Data))
+		for key := range cred.Data {
+			exported.StringData[key] = redactedValue
+		}
+	}
+	return exported, nil
+}
+
+// exportSecrets exports the Secrets referenced by the list items at the
+// given indices, fetching up to --concurrency of them at once. The
+// results are in the order of the indices, and nil for the items that
+// don't refer to a Secret.
+func exportSecrets(ctx context.Context, kubeClient client.Client, list exportableWithSecretList, indices []int) ([]interface{}, error) {
+	secrets := make([]interface{}, len(indices))
+	errs := make([]error, len(indices))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < exportArgs.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range work {
+				if secretRef := list.secretItem(indices[n]); secretRef != nil {
+					secrets[n], errs[n] = exportSecret(ctx, kubeClient, *secretRef, exportSourceRedacted)
+				}
+			}
+		}()
+	}
+	for n := range indices {
+		work <- n
+	}
+	close(work)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return secrets, nil
+}
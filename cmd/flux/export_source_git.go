@@ -17,94 +17,54 @@ limitations under the License.
 package main
 
 import (
-	"context"
-	"fmt"
-
 	"github.com/spf13/cobra"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/yaml"
 
-	"github.com/fluxcd/flux2/internal/utils"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 )
 
 var exportSourceGitCmd = &cobra.Command{
-	Use:   "git [name]",
-	Short: "Export GitRepository sources in YAML format",
-	Long:  "The export source git command exports one or all GitRepository sources in YAML format.",
+	Use:               "git [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.GitRepositoryList{}),
+	Short:             "Export GitRepository sources in YAML format",
+	Long:              "The export source git command exports one or all GitRepository sources in YAML format.",
 	Example: `  # Export all GitRepository sources
   flux export source git --all > sources.yaml
 
+  # Export the GitRepository sources of all namespaces
+  flux export source git --all --all-namespaces > sources.yaml
+
   # Export a GitRepository source including the SSH key pair or basic auth credentials
   flux export source git my-private-repo --with-credentials > source.yaml
-`,
-	RunE: exportSourceGitCmdRun,
-}
 
-func init() {
-	exportSourceCmd.AddCommand(exportSourceGitCmd)
-}
+  # Export all GitRepository sources as a JSON array
+  flux export source git --all --output json > sources.json
 
-func exportSourceGitCmdRun(cmd *cobra.Command, args []string) error {
-	if !exportArgs.all && len(args) < 1 {
-		return fmt.Errorf("name is required")
-	}
+  # Export each GitRepository source with its credentials to a separate file
+  flux export source git --all --with-credentials --output-dir=./sources
 
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
+  # Export a GitRepository source and the shape of its credentials, without their values
+  flux export source git my-private-repo --redacted > source.yaml
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
-	if err != nil {
-		return err
-	}
+  # Export the GitRepository sources whose names are listed in a file, one per line
+  flux export source git - < sources.txt > sources.yaml
 
-	if exportArgs.all {
-		var list sourcev1.GitRepositoryList
-		err = kubeClient.List(ctx, &list, client.InNamespace(rootArgs.namespace))
-		if err != nil {
-			return err
-		}
-
-		if len(list.Items) == 0 {
-			logger.Failuref("no source found in %s namespace", rootArgs.namespace)
-			return nil
-		}
-
-		for _, repository := range list.Items {
-			if err := exportGit(repository); err != nil {
-				return err
-			}
-			if exportSourceWithCred {
-				if err := exportGitCredentials(ctx, kubeClient, repository); err != nil {
-					return err
-				}
-			}
-		}
-	} else {
-		name := args[0]
-		namespacedName := types.NamespacedName{
-			Namespace: rootArgs.namespace,
-			Name:      name,
-		}
-		var repository sourcev1.GitRepository
-		err = kubeClient.Get(ctx, namespacedName, &repository)
-		if err != nil {
-			return err
-		}
-		if err := exportGit(repository); err != nil {
-			return err
-		}
-		if exportSourceWithCred {
-			return exportGitCredentials(ctx, kubeClient, repository)
-		}
-	}
-	return nil
+  # Export the GitRepository sources that belong to a team
+  flux export source git --all --label-selector=team=payments > sources.yaml
+`,
+	RunE: exportCommand{
+		apiType: gitRepositoryType,
+		object:  gitRepositoryAdapter{&sourcev1.GitRepository{}},
+		list:    gitRepositoryListAdapter{&sourcev1.GitRepositoryList{}},
+	}.run,
 }
 
-func exportGit(source sourcev1.GitRepository) error {
+func init() {
+	exportSourceCmd.AddCommand(exportSourceGitCmd)
+}
+
+func exportGit(source *sourcev1.GitRepository) interface{} {
 	gvk := sourcev1.GroupVersion.WithKind(sourcev1.GitRepositoryKind)
 	export := sourcev1.GitRepository{
 		TypeMeta: metav1.TypeMeta{
@@ -115,53 +75,35 @@ func exportGit(source sourcev1.GitRepository) error {
 			Name:        source.Name,
 			Namespace:   source.Namespace,
 			Labels:      source.Labels,
-			Annotations: source.Annotations,
+			Annotations: exportAnnotations(source.Annotations),
 		},
 		Spec: source.Spec,
 	}
+	return export
+}
 
-	data, err := yaml.Marshal(export)
-	if err != nil {
-		return err
-	}
+func (ex gitRepositoryAdapter) export() interface{} {
+	return exportGit(ex.GitRepository)
+}
 
-	fmt.Println("---")
-	fmt.Println(resourceToString(data))
-	return nil
+func (ex gitRepositoryListAdapter) exportItem(i int) interface{} {
+	return exportGit(&ex.GitRepositoryList.Items[i])
 }
 
-func exportGitCredentials(ctx context.Context, kubeClient client.Client, source sourcev1.GitRepository) error {
-	if source.Spec.SecretRef != nil {
-		namespacedName := types.NamespacedName{
-			Namespace: source.Namespace,
-			Name:      source.Spec.SecretRef.Name,
-		}
-		var cred corev1.Secret
-		err := kubeClient.Get(ctx, namespacedName, &cred)
-		if err != nil {
-			return fmt.Errorf("failed to retrieve secret %s, error: %w", namespacedName.Name, err)
-		}
-
-		exported := corev1.Secret{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: "v1",
-				Kind:       "Secret",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      namespacedName.Name,
-				Namespace: namespacedName.Namespace,
-			},
-			Data: cred.Data,
-			Type: cred.Type,
-		}
-
-		data, err := yaml.Marshal(exported)
-		if err != nil {
-			return err
-		}
-
-		fmt.Println("---")
-		fmt.Println(resourceToString(data))
+func exportGitSecret(source *sourcev1.GitRepository) *types.NamespacedName {
+	if source.Spec.SecretRef == nil {
+		return nil
 	}
-	return nil
+	return &types.NamespacedName{
+		Namespace: source.Namespace,
+		Name:      source.Spec.SecretRef.Name,
+	}
+}
+
+func (ex gitRepositoryAdapter) secret() *types.NamespacedName {
+	return exportGitSecret(ex.GitRepository)
+}
+
+func (ex gitRepositoryListAdapter) secretItem(i int) *types.NamespacedName {
+	return exportGitSecret(&ex.GitRepositoryList.Items[i])
 }
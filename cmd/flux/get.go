@@ -17,18 +17,34 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
 
 	"github.com/fluxcd/pkg/apis/meta"
 
+	"github.com/fluxcd/flux2/internal/flags"
 	"github.com/fluxcd/flux2/internal/utils"
 )
 
@@ -39,7 +55,24 @@ var getCmd = &cobra.Command{
 }
 
 type GetFlags struct {
-	allNamespaces bool
+	allNamespaces    bool
+	labelSelector    string
+	fieldSelector    string
+	output           flags.GetOutputFormat
+	watch            bool
+	wait             bool
+	pollInterval     time.Duration
+	limit            int64
+	continueToken    string
+	statusSelector   flags.StatusFilter
+	failOnNotReady   bool
+	includeSuspended bool
+	sortBy           flags.SortKey
+	reverse          bool
+	groupByNamespace bool
+	noHeaders        bool
+	messageWidth     int
+	stale            time.Duration
 }
 
 var getArgs GetFlags
@@ -47,22 +80,57 @@ var getArgs GetFlags
 func init() {
 	getCmd.PersistentFlags().BoolVarP(&getArgs.allNamespaces, "all-namespaces", "A", false,
 		"list the requested object(s) across all namespaces")
+	getCmd.PersistentFlags().StringVarP(&getArgs.labelSelector, "label-selector", "l", "",
+		"filter the requested object(s) by label, supports '=', '==', '!=', 'in', 'notin' and '!key'")
+	getCmd.PersistentFlags().StringVar(&getArgs.fieldSelector, "field-selector", "",
+		"filter the requested object(s) by field, supports '=', '==' and '!=', e.g. 'metadata.name=podinfo'")
+	getCmd.PersistentFlags().VarP(&getArgs.output, "output", "o", getArgs.output.Description())
+	getCmd.PersistentFlags().BoolVarP(&getArgs.watch, "watch", "w", false,
+		"after listing the requested object(s), refresh the list every poll interval")
+	getCmd.PersistentFlags().BoolVar(&getArgs.wait, "wait", false,
+		"wait for the named object to be ready, printing its status transitions, and fail if it isn't ready within the timeout")
+	getCmd.PersistentFlags().DurationVar(&getArgs.pollInterval, "poll-interval", 2*time.Second,
+		"the interval at which the list is refreshed when watching or waiting")
+	getCmd.PersistentFlags().Int64Var(&getArgs.limit, "limit", 0,
+		"the maximum number of objects to list, a continue token is printed when more objects are available")
+	getCmd.PersistentFlags().StringVar(&getArgs.continueToken, "continue", "",
+		"the continue token of a previous listing, to list the next objects")
+	getCmd.PersistentFlags().Var(&getArgs.statusSelector, "status-selector", getArgs.statusSelector.Description())
+	getCmd.PersistentFlags().BoolVar(&getArgs.failOnNotReady, "fail-on-not-ready", false,
+		"exit with an error when any of the listed objects is not ready, the suspended objects are ignored")
+	getCmd.PersistentFlags().BoolVar(&getArgs.includeSuspended, "include-suspended", false,
+		"do not ignore the suspended objects with --fail-on-not-ready")
+	getCmd.PersistentFlags().Var(&getArgs.sortBy, "sort-by", getArgs.sortBy.Description())
+	getCmd.PersistentFlags().BoolVar(&getArgs.reverse, "reverse", false,
+		"reverse the order of the listed objects")
+	getCmd.PersistentFlags().BoolVar(&getArgs.groupByNamespace, "group-by-namespace", false,
+		"print a table per namespace with --all-namespaces, instead of a namespace column")
+	getCmd.PersistentFlags().BoolVar(&getArgs.noHeaders, "no-headers", false,
+		"do not print the header row of the tables")
+	getCmd.PersistentFlags().IntVar(&getArgs.messageWidth, "message-width", 0,
+		"the maximum width of the message column, longer messages keep their end which usually holds the error, 0 for no truncation")
+	getCmd.PersistentFlags().DurationVar(&getArgs.stale, "stale", 0,
+		"mark the objects last reconciled longer ago than the duration as stale, in an extra column, e.g. 1h")
 	rootCmd.AddCommand(getCmd)
 }
 
 type summarisable interface {
 	listAdapter
-	summariseItem(i int, includeNamespace bool, includeKind bool) []string
-	headers(includeNamespace bool) []string
+	summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string
+	headers(includeNamespace bool, wide bool) []string
 }
 
 // --- these help with implementations of summarisable
 
+// waitingMessage is the message of the objects without a Ready
+// condition, which are not reconciled yet.
+const waitingMessage = "waiting to be reconciled"
+
 func statusAndMessage(conditions []metav1.Condition) (string, string) {
 	if c := apimeta.FindStatusCondition(conditions, meta.ReadyCondition); c != nil {
 		return string(c.Status), c.Message
 	}
-	return string(metav1.ConditionFalse), "waiting to be reconciled"
+	return string(metav1.ConditionFalse), waitingMessage
 }
 
 func nameColumns(item named, includeNamespace bool, includeKind bool) []string {
@@ -80,53 +148,772 @@ func nameColumns(item named, includeNamespace bool, includeKind bool) []string {
 
 var namespaceHeader = []string{"Namespace"}
 
+// ageHeader is the header of the column appended to the tables, which
+// tells the time since the creation of the objects.
+const ageHeader = "Age"
+
+// objectAge returns the time since the creation of the object, in the
+// compact format of kubectl, e.g. 3d4h.
+func objectAge(object client.Object) string {
+	if object == nil {
+		return ""
+	}
+	created := object.GetCreationTimestamp()
+	if created.IsZero() {
+		return ""
+	}
+	return duration.HumanDuration(time.Since(created.Time))
+}
+
+// staleHeader is the header of the column appended to the tables with
+// --stale, which marks the objects that weren't reconciled recently.
+const staleHeader = "Stale"
+
+// objectHeaders returns the headers of the columns appended to the
+// tables, which are filled by objectColumns.
+func objectHeaders() []string {
+	if getArgs.stale > 0 {
+		return []string{ageHeader, staleHeader}
+	}
+	return []string{ageHeader}
+}
+
+// objectColumns returns the age of the object, and whether it is stale
+// with --stale.
+func objectColumns(object client.Object) []string {
+	if getArgs.stale > 0 {
+		return []string{objectAge(object), staleMarker(object, getArgs.stale)}
+	}
+	return []string{objectAge(object)}
+}
+
+// staleMarker returns STALE when the object was last reconciled longer
+// ago than the duration, which tells that its reconciliation stalled
+// even when it is still ready from a past success. The objects not
+// reconciled yet aren't marked.
+func staleMarker(object client.Object, stale time.Duration) string {
+	if object == nil {
+		return ""
+	}
+	last := lastReconciled(object)
+	if last.IsZero() || time.Since(last) <= stale {
+		return ""
+	}
+	return "STALE"
+}
+
+// lastReconciled returns the latest of the last transition of the Ready
+// condition and the last handled reconcile request of the object.
+func lastReconciled(object client.Object) time.Time {
+	last := lastReadyTransition(object)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return last
+	}
+	handled, _, _ := unstructured.NestedString(content, "status", "lastHandledReconcileAt")
+	if t, err := time.Parse(time.RFC3339Nano, handled); err == nil && t.After(last) {
+		return t
+	}
+	return last
+}
+
+// labelSelectorOption parses a label selector, which may contain
+// set-based requirements, into a list option.
+func labelSelectorOption(selector string) (client.ListOption, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector '%s': %w", selector, err)
+	}
+	return client.MatchingLabelsSelector{Selector: s}, nil
+}
+
 type getCommand struct {
 	apiType
 	list summarisable
 }
 
 func (get getCommand) run(cmd *cobra.Command, args []string) error {
+	if getArgs.failOnNotReady && getArgs.watch {
+		return fmt.Errorf("--fail-on-not-ready can't be used when watching")
+	}
+	if delimitedOutput() && getArgs.watch {
+		return fmt.Errorf("%s output can't be used when watching", getArgs.output)
+	}
+	if getArgs.messageWidth < 0 {
+		return fmt.Errorf("--message-width must not be negative")
+	}
+	if err := validateListLimit(false); err != nil {
+		return err
+	}
+	if err := validateGroupByNamespace(); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		if getArgs.watch || getArgs.wait {
+			return fmt.Errorf("%s output can't be used when watching or waiting", getArgs.output)
+		}
+		if len(splitContexts(rootArgs.kubecontext)) > 1 {
+			return fmt.Errorf("%s output can't be used with multiple contexts", getArgs.output)
+		}
+		kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+		if err != nil {
+			return err
+		}
+		return get.printObjects(kubeClient, args)
+	}
+
+	if getArgs.wait {
+		if len(args) != 1 {
+			return fmt.Errorf("--wait requires the name of a single object")
+		}
+		if getArgs.watch {
+			return fmt.Errorf("--wait can't be used when watching")
+		}
+		if len(splitContexts(rootArgs.kubecontext)) > 1 {
+			return fmt.Errorf("--wait can't be used with multiple contexts")
+		}
+		kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+		if err != nil {
+			return err
+		}
+		return get.waitForReady(kubeClient, args[0])
+	}
+
+	var table func() ([]string, [][]string, error)
+	if contexts := splitContexts(rootArgs.kubecontext); len(contexts) > 1 {
+		if getArgs.continueToken != "" {
+			return fmt.Errorf("a continue token can't be used with multiple contexts")
+		}
+		table = func() ([]string, [][]string, error) {
+			return get.contextsTable(args, contexts, false)
+		}
+	} else {
+		kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+		if err != nil {
+			return err
+		}
+		table = func() ([]string, [][]string, error) {
+			return get.table(kubeClient, args, false)
+		}
+	}
+
+	if getArgs.statusSelector != "" {
+		list := table
+		table = func() ([]string, [][]string, error) {
+			header, rows, err := list()
+			return header, filterStatusRows(header, rows, getArgs.statusSelector), err
+		}
+	}
+
+	if getArgs.watch {
+		return watchTable(table)
+	}
+
+	header, rows, err := table()
+	if err != nil {
+		return err
+	}
+
+	if len(rows) == 0 {
+		if get.list.asClientList().GetContinue() == "" {
+			logger.Failuref("no %s objects found in %s namespace", get.kind, rootArgs.namespace)
+		}
+	} else {
+		printGetTable(os.Stdout, header, rows)
+	}
+	printContinueToken(get.list.asClientList(), get.kind)
+
+	if getArgs.failOnNotReady {
+		return notReadyError(countNotReady(header, rows), len(rows))
+	}
+	return nil
+}
+
+// structuredOutput tells whether the objects are printed in full as
+// JSON or YAML, instead of a table.
+func structuredOutput() bool {
+	return getArgs.output == "json" || getArgs.output == "yaml"
+}
+
+// printObjects prints the listed objects in full in the output format,
+// a single object when a name is given and a list otherwise. The
+// objects are filtered and sorted as the rows of the table would be.
+func (get getCommand) printObjects(kubeClient client.Client, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
 
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err := get.listObjects(ctx, kubeClient, args); err != nil {
+		return err
+	}
+	list := get.list.asClientList()
+	items, err := apimeta.ExtractList(list)
 	if err != nil {
 		return err
 	}
 
-	var listOpts []client.ListOption
-	if !getArgs.allNamespaces {
-		listOpts = append(listOpts, client.InNamespace(rootArgs.namespace))
+	header := get.list.headers(false, false)
+	var rows [][]string
+	var objects []runtime.Object
+	for i, item := range items {
+		row := get.list.summariseItem(i, false, false, false)
+		if getArgs.statusSelector != "" && len(filterStatusRows(header, [][]string{row}, getArgs.statusSelector)) == 0 {
+			continue
+		}
+		rows = append(rows, row)
+		objects = append(objects, item)
+	}
+	if getArgs.sortBy != "" || getArgs.reverse {
+		sort.SliceStable(objects, func(i, j int) bool {
+			return objectLess(objects[i].(client.Object), objects[j].(client.Object))
+		})
 	}
 
+	scheme := utils.NewScheme()
+	for _, object := range objects {
+		gvk, err := apiutil.GVKForObject(object, scheme)
+		if err != nil {
+			return err
+		}
+		object.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	gvk, err := apiutil.GVKForObject(list, scheme)
+	if err != nil {
+		return err
+	}
+	list.GetObjectKind().SetGroupVersionKind(gvk)
+	if err := apimeta.SetList(list, objects); err != nil {
+		return err
+	}
+
+	var output interface{} = list
 	if len(args) > 0 {
-		listOpts = append(listOpts, client.MatchingFields{"metadata.name": args[0]})
+		if len(objects) == 0 {
+			return fmt.Errorf("%s object '%s' not found in %s namespace", get.kind, args[0], rootArgs.namespace)
+		}
+		output = objects[0]
 	}
 
-	err = kubeClient.List(ctx, get.list.asClientList(), listOpts...)
+	var data []byte
+	if getArgs.output == "json" {
+		data, err = json.MarshalIndent(output, "", "  ")
+	} else {
+		data, err = yaml.Marshal(output)
+	}
 	if err != nil {
 		return err
 	}
+	fmt.Println(strings.TrimSuffix(string(data), "\n"))
 
-	getAll := cmd.Use == "all"
+	printContinueToken(list, get.kind)
+	if getArgs.failOnNotReady {
+		return notReadyError(countNotReady(header, rows), len(rows))
+	}
+	return nil
+}
 
-	if get.list.len() == 0 {
-		if !getAll {
-			logger.Failuref("no %s objects found in %s namespace", get.kind, rootArgs.namespace)
+// countNotReady returns the number of rows of which the Ready column is
+// not True. The suspended objects are not counted, unless requested.
+func countNotReady(header []string, rows [][]string) int {
+	ready, suspended := -1, -1
+	for i, h := range header {
+		switch h {
+		case "Ready":
+			ready = i
+		case "Suspended":
+			suspended = i
+		}
+	}
+	if ready < 0 {
+		return 0
+	}
+
+	var count int
+	for _, row := range rows {
+		if suspended >= 0 && row[suspended] == "True" && !getArgs.includeSuspended {
+			continue
+		}
+		if row[ready] != string(metav1.ConditionTrue) {
+			count++
+		}
+	}
+	return count
+}
+
+// notReadyError reports the objects that are not ready for
+// --fail-on-not-ready, for the command to exit with an error.
+func notReadyError(notReady, total int) error {
+	if notReady == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d objects are not ready", notReady, total)
+}
+
+// filterStatusRows returns the rows of the objects with the given
+// status, based on their Ready and Suspended columns. The objects not
+// reconciled yet are reconciling rather than failed, they are told apart
+// from the failed ones by their waitingMessage.
+func filterStatusRows(header []string, rows [][]string, status flags.StatusFilter) [][]string {
+	if status == "" {
+		return rows
+	}
+
+	column := "Ready"
+	if status == "suspended" {
+		column = "Suspended"
+	}
+	index, message := -1, -1
+	for i, h := range header {
+		switch h {
+		case column:
+			index = i
+		case "Message":
+			message = i
 		}
+	}
+	if index < 0 {
+		return nil
+	}
+
+	match := func(row []string) bool {
+		value := row[index]
+		waiting := value == string(metav1.ConditionFalse) && message >= 0 && row[message] == waitingMessage
+		switch status {
+		case "ready":
+			return value == string(metav1.ConditionTrue)
+		case "not-ready":
+			return value != string(metav1.ConditionTrue)
+		case "reconciling":
+			return value == string(metav1.ConditionUnknown) || waiting
+		case "failed":
+			return value == string(metav1.ConditionFalse) && !waiting
+		default: // suspended
+			return value == "True"
+		}
+	}
+
+	var result [][]string
+	for _, row := range rows {
+		if match(row) {
+			result = append(result, row)
+		}
+	}
+	return result
+}
+
+// validateListLimit checks the pagination flags. A continue token is
+// specific to the kind it was returned for, so it can't be used when
+// listing all kinds.
+func validateListLimit(getAll bool) error {
+	if getArgs.limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	if getAll && getArgs.continueToken != "" {
+		return fmt.Errorf("a continue token can't be used when listing all kinds")
+	}
+	return nil
+}
+
+// validateGroupByNamespace checks that the tables grouped by namespace
+// are printed for objects of all namespaces, and are not redrawn.
+func validateGroupByNamespace() error {
+	if !getArgs.groupByNamespace {
 		return nil
 	}
+	if !getArgs.allNamespaces {
+		return fmt.Errorf("--group-by-namespace requires --all-namespaces")
+	}
+	if getArgs.watch || structuredOutput() || delimitedOutput() {
+		return fmt.Errorf("--group-by-namespace can only be used with the table output")
+	}
+	return nil
+}
+
+// printGetTable prints the table of the get commands. With
+// --group-by-namespace, the rows are printed in a table per namespace
+// without the namespace column, each table being preceded by the
+// namespace and its number of objects.
+func printGetTable(w io.Writer, header []string, rows [][]string) {
+	column := -1
+	for i, h := range header {
+		if h == namespaceHeader[0] {
+			column = i
+			break
+		}
+	}
+	if !getArgs.groupByNamespace || column < 0 {
+		printTable(w, header, rows)
+		return
+	}
+
+	without := func(row []string) []string {
+		return append(append([]string{}, row[:column]...), row[column+1:]...)
+	}
+	groups := make(map[string][][]string)
+	var namespaces []string
+	for _, row := range rows {
+		namespace := row[column]
+		if _, ok := groups[namespace]; !ok {
+			namespaces = append(namespaces, namespace)
+		}
+		groups[namespace] = append(groups[namespace], without(row))
+	}
+	sort.Strings(namespaces)
+
+	for i, namespace := range namespaces {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Namespace: %s (%d)\n", namespace, len(groups[namespace]))
+		printTable(w, without(header), groups[namespace])
+	}
+}
+
+// delimitedOutput tells whether the tables are printed as comma or tab
+// separated values, instead of aligned columns.
+func delimitedOutput() bool {
+	return getArgs.output == "csv" || getArgs.output == "tsv"
+}
+
+// printDelimited prints the table as comma or tab separated values.
+// The values containing the separator, a quote or a new line are quoted.
+func printDelimited(w io.Writer, header []string, rows [][]string) {
+	writer := csv.NewWriter(w)
+	if getArgs.output == "tsv" {
+		writer.Comma = '\t'
+	}
+	if !getArgs.noHeaders {
+		writer.Write(header)
+	}
+	writer.WriteAll(rows)
+}
+
+// printTable prints the table, without its header row with
+// --no-headers. The header is rendered in both cases, so that the
+// columns have the same width.
+func printTable(w io.Writer, header []string, rows [][]string) {
+	if delimitedOutput() {
+		printDelimited(w, header, rows)
+		return
+	}
+	rows = truncateMessages(header, rows, getArgs.messageWidth)
+	if !getArgs.noHeaders {
+		utils.PrintTable(w, header, rows)
+		return
+	}
+	var buf bytes.Buffer
+	utils.PrintTable(&buf, header, rows)
+	if lines := strings.SplitN(buf.String(), "\n", 2); len(lines) == 2 {
+		fmt.Fprint(w, lines[1])
+	}
+}
+
+// truncateMessages returns a copy of the rows with the values of the
+// message column cut to the width, keeping their end. A width of 0
+// keeps the messages whole.
+func truncateMessages(header []string, rows [][]string, width int) [][]string {
+	column := -1
+	for i, name := range header {
+		if name == "Message" {
+			column = i
+		}
+	}
+	if width <= 0 || column < 0 {
+		return rows
+	}
 
-	header := get.list.headers(getArgs.allNamespaces)
+	truncated := make([][]string, len(rows))
+	for i, row := range rows {
+		truncated[i] = row
+		if column >= len(row) {
+			continue
+		}
+		if message := []rune(row[column]); len(message) > width {
+			truncated[i] = append([]string(nil), row...)
+			truncated[i][column] = truncateStart(message, width)
+		}
+	}
+	return truncated
+}
+
+// truncateStart cuts the start of the message to fit the width,
+// starting with an ellipsis when there is room for it.
+func truncateStart(message []rune, width int) string {
+	const ellipsis = "..."
+	if width <= len(ellipsis) {
+		return string(message[len(message)-width:])
+	}
+	return ellipsis + string(message[len(message)-width+len(ellipsis):])
+}
+
+// printContinueToken tells how to list the next objects when the list
+// was truncated by the limit.
+func printContinueToken(list client.ObjectList, kind string) {
+	token := list.GetContinue()
+	if token == "" {
+		return
+	}
+	if remaining := list.GetRemainingItemCount(); remaining != nil {
+		logger.Warningf("%d more %s objects, list them with --continue=%s", *remaining, kind, token)
+		return
+	}
+	logger.Warningf("more %s objects, list them with --continue=%s", kind, token)
+}
+
+// table lists the objects and returns the header and the rows of
+// their table.
+func (get getCommand) table(kubeClient client.Client, args []string, includeKind bool) ([]string, [][]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	if err := get.listObjects(ctx, kubeClient, args); err != nil {
+		return nil, nil, err
+	}
+
+	items, err := apimeta.ExtractList(get.list.asClientList())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wide := getArgs.output == "wide"
+	header := append(get.list.headers(getArgs.allNamespaces, wide), objectHeaders()...)
 	var rows [][]string
+	var objects []client.Object
 	for i := 0; i < get.list.len(); i++ {
-		row := get.list.summariseItem(i, getArgs.allNamespaces, getAll)
-		rows = append(rows, row)
+		object := items[i].(client.Object)
+		row := get.list.summariseItem(i, getArgs.allNamespaces, includeKind, wide)
+		rows = append(rows, append(row, objectColumns(object)...))
+		objects = append(objects, object)
 	}
-	utils.PrintTable(os.Stdout, header, rows)
+	sortObjectRows(objects, rows)
+	return header, rows, nil
+}
 
-	if getAll {
-		fmt.Println()
+// sortObjectRows sorts the rows by the --sort-by key of their objects,
+// keeping the listing order of the objects with equal keys. The rows
+// without an object, such as the context errors, are sorted first.
+func sortObjectRows(objects []client.Object, rows [][]string) {
+	if getArgs.sortBy == "" && !getArgs.reverse {
+		return
+	}
+	indices := make([]int, len(rows))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return objectLess(objects[indices[i]], objects[indices[j]])
+	})
+
+	sorted := make([][]string, len(rows))
+	for i, index := range indices {
+		sorted[i] = rows[index]
+	}
+	copy(rows, sorted)
+}
+
+// objectLess reports whether the object a sorts before b by the
+// --sort-by key, which defaults to the namespace and name, in reverse with --reverse.
+func objectLess(a, b client.Object) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	if getArgs.reverse {
+		a, b = b, a
+	}
+
+	switch getArgs.sortBy {
+	case "name":
+		return a.GetName() < b.GetName()
+	case "namespace", "":
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	case "ready":
+		return readyRank(a) < readyRank(b)
+	case "last-applied":
+		return lastReadyTransition(a).Before(lastReadyTransition(b))
+	}
+	return false
+}
+
+// readyRank orders the objects by their Ready condition, with the
+// failed objects first and the ready ones last.
+func readyRank(object client.Object) int {
+	if c := readyCondition(object); c != nil {
+		switch c.Status {
+		case metav1.ConditionUnknown:
+			return 1
+		case metav1.ConditionTrue:
+			return 2
+		}
+	}
+	return 0
+}
+
+// lastReadyTransition returns the time of the last transition of the
+// Ready condition, which is when the object was last reconciled with a
+// different outcome. It is zero for the objects not reconciled yet.
+func lastReadyTransition(object client.Object) time.Time {
+	if c := readyCondition(object); c != nil {
+		return c.LastTransitionTime.Time
+	}
+	return time.Time{}
+}
+
+func readyCondition(object client.Object) *metav1.Condition {
+	if s, ok := object.(interface {
+		GetStatusConditions() *[]metav1.Condition
+	}); ok {
+		return apimeta.FindStatusCondition(*s.GetStatusConditions(), meta.ReadyCondition)
 	}
 	return nil
 }
+
+// contextsTable lists the objects in each of the contexts concurrently,
+// and returns a single table prefixed with a context column. The
+// contexts that can't be listed are shown as an error row.
+func (get getCommand) contextsTable(args []string, contexts []string, includeKind bool) ([]string, [][]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	listOpts, err := get.listOptions(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lists := make([]client.ObjectList, len(contexts))
+	errs := make([]error, len(contexts))
+	var wg sync.WaitGroup
+	for i, kubecontext := range contexts {
+		wg.Add(1)
+		go func(i int, kubecontext string) {
+			defer wg.Done()
+			kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, kubecontext)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			list := get.list.asClientList().DeepCopyObject().(client.ObjectList)
+			errs[i] = fieldSelectorError(kubeClient.List(ctx, list, listOpts...))
+			lists[i] = list
+		}(i, kubecontext)
+	}
+	wg.Wait()
+
+	wide := getArgs.output == "wide"
+	header := append([]string{"Context"}, get.list.headers(getArgs.allNamespaces, wide)...)
+	header = append(header, objectHeaders()...)
+	var rows [][]string
+	var objects []client.Object
+	for i, kubecontext := range contexts {
+		if errs[i] != nil {
+			rows = append(rows, contextErrorRow(header, kubecontext, errs[i]))
+			objects = append(objects, nil)
+			continue
+		}
+		if lists[i].GetContinue() != "" {
+			logger.Warningf("the %s objects of context %s are truncated to %d", get.kind, kubecontext, getArgs.limit)
+		}
+		items, err := apimeta.ExtractList(lists[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := apimeta.SetList(get.list.asClientList(), items); err != nil {
+			return nil, nil, err
+		}
+		for j := 0; j < get.list.len(); j++ {
+			object := items[j].(client.Object)
+			row := get.list.summariseItem(j, getArgs.allNamespaces, includeKind, wide)
+			row = append(append([]string{kubecontext}, row...), objectColumns(object)...)
+			rows = append(rows, row)
+			objects = append(objects, object)
+		}
+	}
+	sortObjectRows(objects, rows)
+	return header, rows, nil
+}
+
+// splitContexts splits a comma-separated list of kubeconfig contexts.
+func splitContexts(kubecontext string) []string {
+	var contexts []string
+	for _, c := range strings.Split(kubecontext, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			contexts = append(contexts, c)
+		}
+	}
+	return contexts
+}
+
+// contextErrorRow returns a row for a context that failed to be
+// listed, with the error in the message column.
+func contextErrorRow(header []string, kubecontext string, err error) []string {
+	row := make([]string, len(header))
+	row[0] = kubecontext
+	for i, h := range header {
+		switch h {
+		case "Ready":
+			row[i] = string(metav1.ConditionFalse)
+		case "Message":
+			row[i] = err.Error()
+		}
+	}
+	return row
+}
+
+// listObjects fills the list with the objects selected by the
+// namespace and label selector flags, and the name in args if given.
+func (get getCommand) listObjects(ctx context.Context, kubeClient client.Client, args []string) error {
+	listOpts, err := get.listOptions(args)
+	if err != nil {
+		return err
+	}
+	return fieldSelectorError(kubeClient.List(ctx, get.list.asClientList(), listOpts...))
+}
+
+// fieldSelectorError tells that the field selector is the cause of a
+// bad request, as the custom resources only support a few fields.
+func fieldSelectorError(err error) error {
+	if err != nil && getArgs.fieldSelector != "" && apierrors.IsBadRequest(err) {
+		return fmt.Errorf("field selector '%s' is not supported: %w", getArgs.fieldSelector, err)
+	}
+	return err
+}
+
+func (get getCommand) listOptions(args []string) ([]client.ListOption, error) {
+	var listOpts []client.ListOption
+	if !getArgs.allNamespaces {
+		listOpts = append(listOpts, client.InNamespace(rootArgs.namespace))
+	}
+
+	selector := fields.Everything()
+	if getArgs.fieldSelector != "" {
+		s, err := fields.ParseSelector(getArgs.fieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field selector '%s': %w", getArgs.fieldSelector, err)
+		}
+		selector = s
+	}
+	if len(args) > 0 {
+		selector = fields.AndSelectors(selector, fields.OneTermEqualSelector("metadata.name", args[0]))
+	}
+	if !selector.Empty() {
+		listOpts = append(listOpts, client.MatchingFieldsSelector{Selector: selector})
+	}
+
+	if getArgs.labelSelector != "" {
+		selector, err := labelSelectorOption(getArgs.labelSelector)
+		if err != nil {
+			return nil, err
+		}
+		listOpts = append(listOpts, selector)
+	}
+
+	if getArgs.limit > 0 {
+		listOpts = append(listOpts, client.Limit(getArgs.limit))
+	}
+	if getArgs.continueToken != "" {
+		listOpts = append(listOpts, client.Continue(getArgs.continueToken))
+	}
+	return listOpts, nil
+}
@@ -17,17 +17,9 @@ limitations under the License.
 package main
 
 import (
-	"context"
-	"os"
-
 	"github.com/spf13/cobra"
-	apimeta "k8s.io/apimachinery/pkg/api/meta"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/fluxcd/flux2/internal/utils"
 	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
-	"github.com/fluxcd/pkg/apis/meta"
 )
 
 var getAlertProviderCmd = &cobra.Command{
@@ -38,62 +30,34 @@ var getAlertProviderCmd = &cobra.Command{
 	Example: `  # List all Providers and their status
   flux get alert-providers
 `,
-	RunE: getAlertProviderCmdRun,
+	RunE: getCommand{
+		apiType: alertProviderType,
+		list:    alertProviderListAdapter{&notificationv1.ProviderList{}},
+	}.run,
 }
 
 func init() {
 	getCmd.AddCommand(getAlertProviderCmd)
 }
 
-func getAlertProviderCmdRun(cmd *cobra.Command, args []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
-
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
-	if err != nil {
-		return err
-	}
-
-	var listOpts []client.ListOption
-	if !getArgs.allNamespaces {
-		listOpts = append(listOpts, client.InNamespace(rootArgs.namespace))
-	}
-	var list notificationv1.ProviderList
-	err = kubeClient.List(ctx, &list, listOpts...)
-	if err != nil {
-		return err
-	}
-
-	if len(list.Items) == 0 {
-		logger.Failuref("no providers found in %s namespace", rootArgs.namespace)
-		return nil
+func (a alertProviderListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
+	item := a.Items[i]
+	status, msg := statusAndMessage(item.Status.Conditions)
+	row := append(nameColumns(&item, includeNamespace, includeKind),
+		status, msg)
+	if wide {
+		row = append(row, item.Spec.Type)
 	}
+	return row
+}
 
-	header := []string{"Name", "Ready", "Message"}
-	if getArgs.allNamespaces {
-		header = append([]string{"Namespace"}, header...)
+func (a alertProviderListAdapter) headers(includeNamespace bool, wide bool) []string {
+	headers := []string{"Name", "Ready", "Message"}
+	if wide {
+		headers = append(headers, "Type")
 	}
-	var rows [][]string
-	for _, provider := range list.Items {
-		row := []string{}
-		if c := apimeta.FindStatusCondition(provider.Status.Conditions, meta.ReadyCondition); c != nil {
-			row = []string{
-				provider.GetName(),
-				string(c.Status),
-				c.Message,
-			}
-		} else {
-			row = []string{
-				provider.GetName(),
-				string(metav1.ConditionFalse),
-				"waiting to be reconciled",
-			}
-		}
-		if getArgs.allNamespaces {
-			row = append([]string{provider.Namespace}, row...)
-		}
-		rows = append(rows, row)
+	if includeNamespace {
+		headers = append([]string{"Namespace"}, headers...)
 	}
-	utils.PrintTable(os.Stdout, header, rows)
-	return nil
+	return headers
 }
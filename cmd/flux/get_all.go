@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	autov1 "github.com/fluxcd/image-automation-controller/api/v1alpha1"
+	imagev1 "github.com/fluxcd/image-reflector-controller/api/v1alpha1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var getAllCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Get the status of all Flux objects",
+	Long: `The get all command prints the statuses of the objects of every Flux kind,
+grouped in sections. The sections without objects are skipped.`,
+	Example: `  # List all Flux objects in a namespace
+  flux get all --namespace=flux-system
+
+  # List the failing Flux objects in all namespaces
+  flux get all --all-namespaces --status-selector=failed
+
+  # List the Flux objects in all namespaces, the most recently reconciled first
+  flux get all --all-namespaces --sort-by=last-applied --reverse
+`,
+	RunE: getAllCmdRun,
+}
+
+func init() {
+	getCmd.AddCommand(getAllCmd)
+}
+
+type getAllSection struct {
+	title    string
+	commands []getCommand
+}
+
+var getAllSections = []getAllSection{
+	{
+		title: "Sources",
+		commands: []getCommand{
+			{apiType: gitRepositoryType, list: &gitRepositoryListAdapter{&sourcev1.GitRepositoryList{}}},
+			{apiType: helmRepositoryType, list: &helmRepositoryListAdapter{&sourcev1.HelmRepositoryList{}}},
+			{apiType: helmChartType, list: &helmChartListAdapter{&sourcev1.HelmChartList{}}},
+			{apiType: bucketType, list: &bucketListAdapter{&sourcev1.BucketList{}}},
+		},
+	},
+	{
+		title: "Kustomizations",
+		commands: []getCommand{
+			{apiType: kustomizationType, list: &kustomizationListAdapter{&kustomizev1.KustomizationList{}}},
+		},
+	},
+	{
+		title: "Helm releases",
+		commands: []getCommand{
+			{apiType: helmReleaseType, list: &helmReleaseListAdapter{&helmv2.HelmReleaseList{}}},
+		},
+	},
+	{
+		title: "Notifications",
+		commands: []getCommand{
+			{apiType: alertProviderType, list: alertProviderListAdapter{&notificationv1.ProviderList{}}},
+			{apiType: alertType, list: alertListAdapter{&notificationv1.AlertList{}}},
+			{apiType: receiverType, list: receiverListAdapter{&notificationv1.ReceiverList{}}},
+		},
+	},
+	{
+		title: "Image automation",
+		commands: []getCommand{
+			{apiType: imageRepositoryType, list: imageRepositoryListAdapter{&imagev1.ImageRepositoryList{}}},
+			{apiType: imagePolicyType, list: imagePolicyListAdapter{&imagev1.ImagePolicyList{}}},
+			{apiType: imageUpdateAutomationType, list: imageUpdateAutomationListAdapter{&autov1.ImageUpdateAutomationList{}}},
+		},
+	},
+}
+
+func getAllCmdRun(cmd *cobra.Command, args []string) error {
+	if err := validateGetAll(); err != nil {
+		return err
+	}
+	if delimitedOutput() {
+		return fmt.Errorf("%s output is not supported when listing all kinds", getArgs.output)
+	}
+
+	var kubeClient client.Client
+	contexts := splitContexts(rootArgs.kubecontext)
+	if len(contexts) <= 1 {
+		var err error
+		kubeClient, err = utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+		if err != nil {
+			return err
+		}
+	}
+
+	found := false
+	var notReady, total int
+	for _, section := range getAllSections {
+		printed := false
+		for _, c := range section.commands {
+			var header []string
+			var rows [][]string
+			var err error
+			if len(contexts) > 1 {
+				header, rows, err = c.contextsTable(args, contexts, true)
+			} else {
+				header, rows, err = c.table(kubeClient, args, true)
+			}
+			if err != nil {
+				// the optional components, such as image automation, may not be installed
+				if !apimeta.IsNoMatchError(err) {
+					logger.Failuref(err.Error())
+				}
+				continue
+			}
+			printContinueToken(c.list.asClientList(), c.kind)
+
+			rows = filterStatusRows(header, rows, getArgs.statusSelector)
+			if len(rows) == 0 {
+				continue
+			}
+
+			if found {
+				fmt.Println()
+			}
+			if !printed {
+				fmt.Printf("%s:\n", section.title)
+				printed = true
+			}
+			printGetTable(os.Stdout, header, rows)
+			found = true
+			notReady += countNotReady(header, rows)
+			total += len(rows)
+		}
+	}
+
+	if !found {
+		logger.Failuref("no objects found in %s namespace", rootArgs.namespace)
+	}
+	if getArgs.failOnNotReady {
+		return notReadyError(notReady, total)
+	}
+	return nil
+}
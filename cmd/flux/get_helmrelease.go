@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +32,12 @@ var getHelmReleaseCmd = &cobra.Command{
 	Long:    "The get helmreleases command prints the statuses of the resources.",
 	Example: `  # List all Helm releases and their status
   flux get helmreleases
+
+  # List the Helm releases of all namespaces a hundred at a time
+  flux get helmreleases --all-namespaces --limit=100
+
+  # List the Helm releases with their desired chart version, and whether the deployed version drifted from it
+  flux get helmreleases -o wide
 `,
 	RunE: getCommand{
 		apiType: helmReleaseType,
@@ -42,16 +49,54 @@ func init() {
 	getCmd.AddCommand(getHelmReleaseCmd)
 }
 
-func (a helmReleaseListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool) []string {
+func (a helmReleaseListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
 	item := a.Items[i]
 	revision := item.Status.LastAppliedRevision
 	status, msg := statusAndMessage(item.Status.Conditions)
-	return append(nameColumns(&item, includeNamespace, includeKind),
+	row := append(nameColumns(&item, includeNamespace, includeKind),
 		status, msg, revision, strings.Title(strconv.FormatBool(item.Spec.Suspend)))
+	if wide {
+		row = append(row, item.Spec.Chart.Spec.Chart, item.Spec.Chart.Spec.Version,
+			item.Status.LastAttemptedRevision, strings.Title(strconv.FormatBool(helmReleaseDrifted(item))))
+	}
+	return row
 }
 
-func (a helmReleaseListAdapter) headers(includeNamespace bool) []string {
+// helmReleaseDrifted returns whether the deployed chart version lags
+// the desired one, either because the last attempted revision failed to
+// be applied, or because the applied revision doesn't match the version
+// or the semver range of the chart.
+func helmReleaseDrifted(item helmv2.HelmRelease) bool {
+	applied := item.Status.LastAppliedRevision
+	if applied == "" {
+		return false
+	}
+	if attempted := item.Status.LastAttemptedRevision; attempted != "" && attempted != applied {
+		return true
+	}
+
+	desired := item.Spec.Chart.Spec.Version
+	if desired == "" || desired == "*" {
+		return false
+	}
+	appliedSv, err := semver.NewVersion(applied)
+	if err != nil {
+		return false
+	}
+	if desiredSv, err := semver.StrictNewVersion(desired); err == nil {
+		return !desiredSv.Equal(appliedSv)
+	}
+	if c, err := semver.NewConstraint(desired); err == nil {
+		return !c.Check(appliedSv)
+	}
+	return false
+}
+
+func (a helmReleaseListAdapter) headers(includeNamespace bool, wide bool) []string {
 	headers := []string{"Name", "Ready", "Message", "Revision", "Suspended"}
+	if wide {
+		headers = append(headers, "Chart", "Version", "Last attempted", "Drift")
+	}
 	if includeNamespace {
 		headers = append([]string{"Namespace"}, headers...)
 	}
@@ -17,50 +17,97 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
 	autov1 "github.com/fluxcd/image-automation-controller/api/v1alpha1"
 	imagev1 "github.com/fluxcd/image-reflector-controller/api/v1alpha1"
-	"github.com/spf13/cobra"
 )
 
 var getImageAllCmd = &cobra.Command{
 	Use:   "all",
 	Short: "Get all image statuses",
-	Long:  "The get image sub-commands print the statuses of all image objects.",
+	Long: `The get images all command prints the statuses of all image repositories, policies
+and update automations in a single table.`,
 	Example: `  # List all image objects in a namespace
   flux get images all --namespace=flux-system
 
   # List all image objects in all namespaces
   flux get images all --all-namespaces
 `,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		c := getCommand{
-			apiType: imageRepositoryType,
-			list:    imageRepositoryListAdapter{&imagev1.ImageRepositoryList{}},
-		}
-		if err := c.run(cmd, args); err != nil {
-			logger.Failuref(err.Error())
-		}
+	RunE: getImageAllCmdRun,
+}
 
-		c = getCommand{
-			apiType: imagePolicyType,
-			list:    &imagePolicyListAdapter{&imagev1.ImagePolicyList{}},
-		}
-		if err := c.run(cmd, args); err != nil {
-			logger.Failuref(err.Error())
-		}
+func init() {
+	getImageCmd.AddCommand(getImageAllCmd)
+}
 
-		c = getCommand{
-			apiType: imageUpdateAutomationType,
-			list:    &imageUpdateAutomationListAdapter{&autov1.ImageUpdateAutomationList{}},
-		}
-		if err := c.run(cmd, args); err != nil {
-			logger.Failuref(err.Error())
+// imageDetailed is implemented by the image list adapters to summarise
+// the kind specific status of an item in a single column.
+type imageDetailed interface {
+	summarisable
+	details(i int) string
+}
+
+func getImageAllCmdRun(cmd *cobra.Command, args []string) error {
+	// the name, ready, message and suspended columns are shared by the
+	// image kinds, the others are summarised in a details column
+	shared := []string{"Name", "Ready", "Message", "Suspended"}
+	if getArgs.allNamespaces {
+		shared = append(namespaceHeader, shared...)
+	}
+	header := append(append([]string{}, shared...), "Details")
+
+	return kindsTable{
+		description: "image objects",
+		commands: []getCommand{
+			{apiType: imageRepositoryType, list: imageRepositoryListAdapter{&imagev1.ImageRepositoryList{}}},
+			{apiType: imagePolicyType, list: imagePolicyListAdapter{&imagev1.ImagePolicyList{}}},
+			{apiType: imageUpdateAutomationType, list: imageUpdateAutomationListAdapter{&autov1.ImageUpdateAutomationList{}}},
+		},
+		columns: func(c getCommand, i int) ([]string, []string) {
+			kindHeader := c.list.headers(getArgs.allNamespaces, false)
+			row := c.list.summariseItem(i, getArgs.allNamespaces, false, false)
+			return header, append(selectColumns(kindHeader, row, shared), c.list.(imageDetailed).details(i))
+		},
+	}.run(args)
+}
+
+// selectColumns returns the cells of the row under the names of the
+// header, in the order of the names, with an empty cell for the names
+// missing from the header, e.g. Suspended for the image policies.
+func selectColumns(header []string, row []string, names []string) []string {
+	columns := make([]string, len(names))
+	for i, name := range names {
+		for j, h := range header {
+			if h == name && j < len(row) {
+				columns[i] = row[j]
+			}
 		}
+	}
+	return columns
+}
 
-		return nil
-	},
+func (s imageRepositoryListAdapter) details(i int) string {
+	result := s.Items[i].Status.LastScanResult
+	if result == nil {
+		return "not scanned yet"
+	}
+	return fmt.Sprintf("last scan %s, %d tags", result.ScanTime.Time.Format(time.RFC3339), result.TagCount)
 }
 
-func init() {
-	getImageCmd.AddCommand(getImageAllCmd)
+func (s imagePolicyListAdapter) details(i int) string {
+	if latest := s.Items[i].Status.LatestImage; latest != "" {
+		return fmt.Sprintf("latest image %s", latest)
+	}
+	return "no image selected yet"
+}
+
+func (s imageUpdateAutomationListAdapter) details(i int) string {
+	if lastRun := s.Items[i].Status.LastAutomationRunTime; lastRun != nil {
+		return fmt.Sprintf("last run %s", lastRun.Time.Format(time.RFC3339))
+	}
+	return "not run yet"
 }
@@ -17,6 +17,9 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	imagev1 "github.com/fluxcd/image-reflector-controller/api/v1alpha1"
@@ -31,6 +34,9 @@ var getImagePolicyCmd = &cobra.Command{
 
  # List image policies from all namespaces
   flux get image policy --all-namespaces
+
+  # List image policies with the policy and tag filter that selected their latest image
+  flux get image policy -o wide
 `,
 	RunE: getCommand{
 		apiType: imagePolicyType,
@@ -42,16 +48,62 @@ func init() {
 	getImageCmd.AddCommand(getImagePolicyCmd)
 }
 
-func (s imagePolicyListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool) []string {
+func (s imagePolicyListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
 	item := s.Items[i]
 	status, msg := statusAndMessage(item.Status.Conditions)
-	return append(nameColumns(&item, includeNamespace, includeKind), status, msg, item.Status.LatestImage)
+	latestImage := item.Status.LatestImage
+	if latestImage == "" {
+		latestImage = "-"
+	}
+	row := append(nameColumns(&item, includeNamespace, includeKind), status, latestImage, msg)
+	if wide {
+		row = append(row, item.Spec.ImageRepositoryRef.Name, imagePolicyChoice(item.Spec.Policy), imagePolicyFilter(item.Spec.FilterTags))
+	}
+	return row
 }
 
-func (s imagePolicyListAdapter) headers(includeNamespace bool) []string {
-	headers := []string{"Name", "Ready", "Message", "Latest image"}
+func (s imagePolicyListAdapter) headers(includeNamespace bool, wide bool) []string {
+	headers := []string{"Name", "Ready", "Latest image", "Message"}
+	if wide {
+		headers = append(headers, "Image repository", "Policy", "Filter")
+	}
 	if includeNamespace {
 		return append(namespaceHeader, headers...)
 	}
 	return headers
 }
+
+// imagePolicyChoice returns the policy used to order the tags, e.g.
+// semver:>=1.0.0 or alphabetical:asc.
+func imagePolicyChoice(policy imagev1.ImagePolicyChoice) string {
+	switch {
+	case policy.SemVer != nil:
+		return fmt.Sprintf("semver:%s", policy.SemVer.Range)
+	case policy.Alphabetical != nil:
+		return fmt.Sprintf("alphabetical:%s", orderOrDefault(policy.Alphabetical.Order))
+	case policy.Numerical != nil:
+		return fmt.Sprintf("numerical:%s", orderOrDefault(policy.Numerical.Order))
+	}
+	return "-"
+}
+
+// orderOrDefault returns the sorting order, which defaults to asc.
+func orderOrDefault(order string) string {
+	if order == "" {
+		return "asc"
+	}
+	return order
+}
+
+// imagePolicyFilter returns the pattern filtering the tags, and the
+// extracted capture group when set.
+func imagePolicyFilter(filter *imagev1.TagFilter) string {
+	if filter == nil || filter.Pattern == "" {
+		return "-"
+	}
+	parts := []string{filter.Pattern}
+	if filter.Extract != "" {
+		parts = append(parts, fmt.Sprintf("extract %s", filter.Extract))
+	}
+	return strings.Join(parts, " ")
+}
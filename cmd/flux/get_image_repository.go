@@ -46,19 +46,26 @@ func init() {
 	getImageCmd.AddCommand(getImageRepositoryCmd)
 }
 
-func (s imageRepositoryListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool) []string {
+func (s imageRepositoryListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
 	item := s.Items[i]
 	status, msg := statusAndMessage(item.Status.Conditions)
 	var lastScan string
 	if item.Status.LastScanResult != nil {
 		lastScan = item.Status.LastScanResult.ScanTime.Time.Format(time.RFC3339)
 	}
-	return append(nameColumns(&item, includeNamespace, includeKind),
+	row := append(nameColumns(&item, includeNamespace, includeKind),
 		status, msg, lastScan, strings.Title(strconv.FormatBool(item.Spec.Suspend)))
+	if wide {
+		row = append(row, item.Spec.Image)
+	}
+	return row
 }
 
-func (s imageRepositoryListAdapter) headers(includeNamespace bool) []string {
+func (s imageRepositoryListAdapter) headers(includeNamespace bool, wide bool) []string {
 	headers := []string{"Name", "Ready", "Message", "Last scan", "Suspended"}
+	if wide {
+		headers = append(headers, "Image")
+	}
 	if includeNamespace {
 		return append(namespaceHeader, headers...)
 	}
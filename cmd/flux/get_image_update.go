@@ -46,18 +46,25 @@ func init() {
 	getImageCmd.AddCommand(getImageUpdateCmd)
 }
 
-func (s imageUpdateAutomationListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool) []string {
+func (s imageUpdateAutomationListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
 	item := s.Items[i]
 	status, msg := statusAndMessage(item.Status.Conditions)
 	var lastRun string
 	if item.Status.LastAutomationRunTime != nil {
 		lastRun = item.Status.LastAutomationRunTime.Time.Format(time.RFC3339)
 	}
-	return append(nameColumns(&item, includeNamespace, includeKind), status, msg, lastRun, strings.Title(strconv.FormatBool(item.Spec.Suspend)))
+	row := append(nameColumns(&item, includeNamespace, includeKind), status, msg, lastRun, strings.Title(strconv.FormatBool(item.Spec.Suspend)))
+	if wide {
+		row = append(row, item.Spec.Checkout.GitRepositoryRef.Name, item.Spec.Checkout.Branch)
+	}
+	return row
 }
 
-func (s imageUpdateAutomationListAdapter) headers(includeNamespace bool) []string {
+func (s imageUpdateAutomationListAdapter) headers(includeNamespace bool, wide bool) []string {
 	headers := []string{"Name", "Ready", "Message", "Last run", "Suspended"}
+	if wide {
+		headers = append(headers, "Git repository", "Branch")
+	}
 	if includeNamespace {
 		return append(namespaceHeader, headers...)
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -31,6 +32,42 @@ var getKsCmd = &cobra.Command{
 	Long:    "The get kustomizations command prints the statuses of the resources.",
 	Example: `  # List all kustomizations and their status
   flux get kustomizations
+
+  # List all kustomizations with their source and path
+  flux get kustomizations -o wide
+
+  # List all kustomizations with the number of kinds they apply
+  flux get kustomizations --show-inventory-count
+
+  # List the Kustomizations that are not ready in all namespaces
+  flux get kustomizations --all-namespaces --status-selector=not-ready
+
+  # List all kustomizations with the end of their messages cut to 60 characters
+  flux get kustomizations --message-width=60
+
+  # Mark the kustomizations of all namespaces not reconciled in the last hour as stale
+  flux get kustomizations --all-namespaces --stale=1h
+
+  # Exit with an error when any Kustomization is not ready
+  flux get kustomizations --all-namespaces --fail-on-not-ready
+
+  # List the kustomizations of all namespaces in a table per namespace
+  flux get kustomizations --all-namespaces --group-by-namespace
+
+  # List the kustomizations of all namespaces except flux-system
+  flux get kustomizations --all-namespaces --field-selector=metadata.namespace!=flux-system
+
+  # List all kustomizations from multiple clusters
+  flux get kustomizations --context=dev,staging,prod
+
+  # Save the kustomizations of all namespaces as comma separated values
+  flux get kustomizations --all-namespaces -o csv > kustomizations.csv
+
+  # Print a Kustomization with its status in YAML
+  flux get kustomizations apps -o yaml
+
+  # Wait for a Kustomization reconciled after a push to be ready
+  flux get kustomizations apps --wait --timeout=5m
 `,
 	RunE: getCommand{
 		apiType: kustomizationType,
@@ -38,20 +75,55 @@ var getKsCmd = &cobra.Command{
 	}.run,
 }
 
+type getKustomizationFlags struct {
+	showInventoryCount bool
+}
+
+var getKsArgs getKustomizationFlags
+
 func init() {
+	getKsCmd.Flags().BoolVar(&getKsArgs.showInventoryCount, "show-inventory-count", false,
+		"print the number of kinds recorded per namespace in the status snapshot, which is also printed with -o wide")
 	getCmd.AddCommand(getKsCmd)
 }
 
-func (a kustomizationListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool) []string {
+// snapshotKindCount returns the number of kinds recorded in the snapshot,
+// a kind being counted once per namespace it is applied in.
+func snapshotKindCount(snapshot *kustomizev1.Snapshot) int {
+	if snapshot == nil {
+		return 0
+	}
+	var count int
+	for _, entry := range snapshot.Entries {
+		count += len(entry.Kinds)
+	}
+	return count
+}
+
+func (a kustomizationListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
 	item := a.Items[i]
 	revision := item.Status.LastAppliedRevision
 	status, msg := statusAndMessage(item.Status.Conditions)
-	return append(nameColumns(&item, includeNamespace, includeKind),
+	row := append(nameColumns(&item, includeNamespace, includeKind),
 		status, msg, revision, strings.Title(strconv.FormatBool(item.Spec.Suspend)))
+	if wide {
+		row = append(row, fmt.Sprintf("%s/%s", item.Spec.SourceRef.Kind, item.Spec.SourceRef.Name), item.Spec.Path,
+			item.Annotations[suspendedByAnnotation], item.Annotations[suspendReasonAnnotation])
+	}
+	if wide || getKsArgs.showInventoryCount {
+		row = append(row, strconv.Itoa(snapshotKindCount(item.Status.Snapshot)))
+	}
+	return row
 }
 
-func (a kustomizationListAdapter) headers(includeNamespace bool) []string {
+func (a kustomizationListAdapter) headers(includeNamespace bool, wide bool) []string {
 	headers := []string{"Name", "Ready", "Message", "Revision", "Suspended"}
+	if wide {
+		headers = append(headers, "Source", "Path", "Suspended by", "Reason")
+	}
+	if wide || getKsArgs.showInventoryCount {
+		headers = append(headers, "Inventory kinds")
+	}
 	if includeNamespace {
 		headers = append([]string{"Namespace"}, headers...)
 	}
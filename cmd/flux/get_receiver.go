@@ -17,19 +17,12 @@ limitations under the License.
 package main
 
 import (
-	"context"
-	"os"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
-	apimeta "k8s.io/apimachinery/pkg/api/meta"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/fluxcd/flux2/internal/utils"
 	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
-	"github.com/fluxcd/pkg/apis/meta"
 )
 
 var getReceiverCmd = &cobra.Command{
@@ -39,62 +32,46 @@ var getReceiverCmd = &cobra.Command{
 	Long:    "The get receiver command prints the statuses of the resources.",
 	Example: `  # List all Receiver and their status
   flux get receivers
+
+  # List all Receivers with their full webhook path and events
+  flux get receivers -o wide
 `,
-	RunE: getReceiverCmdRun,
+	RunE: getCommand{
+		apiType: receiverType,
+		list:    receiverListAdapter{&notificationv1.ReceiverList{}},
+	}.run,
 }
 
 func init() {
 	getCmd.AddCommand(getReceiverCmd)
 }
 
-func getReceiverCmdRun(cmd *cobra.Command, args []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
-
-	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
-	if err != nil {
-		return err
-	}
+// receiverWebhookPathLength is the length of the webhook paths in the
+// default table, which is enough to tell the receivers apart.
+const receiverWebhookPathLength = 18
 
-	var listOpts []client.ListOption
-	if !getArgs.allNamespaces {
-		listOpts = append(listOpts, client.InNamespace(rootArgs.namespace))
+func (a receiverListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
+	item := a.Items[i]
+	status, msg := statusAndMessage(item.Status.Conditions)
+	webhook := item.Status.URL
+	if !wide && len(webhook) > receiverWebhookPathLength {
+		webhook = webhook[:receiverWebhookPathLength] + "..."
 	}
-	var list notificationv1.ReceiverList
-	err = kubeClient.List(ctx, &list, listOpts...)
-	if err != nil {
-		return err
-	}
-
-	if len(list.Items) == 0 {
-		logger.Failuref("no receivers found in %s namespace", rootArgs.namespace)
-		return nil
+	row := append(nameColumns(&item, includeNamespace, includeKind),
+		status, msg, strings.Title(strconv.FormatBool(item.Spec.Suspend)), webhook)
+	if wide {
+		row = append(row, item.Spec.Type, strings.Join(item.Spec.Events, ","))
 	}
+	return row
+}
 
-	header := []string{"Name", "Ready", "Message", "Suspended"}
-	if getArgs.allNamespaces {
-		header = append([]string{"Namespace"}, header...)
+func (a receiverListAdapter) headers(includeNamespace bool, wide bool) []string {
+	headers := []string{"Name", "Ready", "Message", "Suspended", "Webhook"}
+	if wide {
+		headers = append(headers, "Type", "Events")
 	}
-	var rows [][]string
-	for _, receiver := range list.Items {
-		var row []string
-		if c := apimeta.FindStatusCondition(receiver.Status.Conditions, meta.ReadyCondition); c != nil {
-			row = []string{
-				receiver.GetName(),
-				string(c.Status),
-				c.Message,
-				strings.Title(strconv.FormatBool(receiver.Spec.Suspend)),
-			}
-		} else {
-			row = []string{
-				receiver.GetName(),
-				string(metav1.ConditionFalse),
-				"waiting to be reconciled",
-				strings.Title(strconv.FormatBool(receiver.Spec.Suspend)),
-			}
-		}
-		rows = append(rows, row)
+	if includeNamespace {
+		headers = append([]string{"Namespace"}, headers...)
 	}
-	utils.PrintTable(os.Stdout, header, rows)
-	return nil
+	return headers
 }
@@ -17,57 +17,189 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/flux2/internal/utils"
 )
 
 var getSourceAllCmd = &cobra.Command{
 	Use:   "all",
 	Short: "Get all source statuses",
-	Long:  "The get sources all command print the statuses of all sources.",
+	Long:  "The get sources all command prints the statuses of all sources in a single table.",
 	Example: `  # List all sources in a namespace
   flux get sources all --namespace=flux-system
 
   # List all sources in all namespaces
   flux get sources all --all-namespaces
 `,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		c := getCommand{
-			apiType: bucketType,
-			list:    &bucketListAdapter{&sourcev1.BucketList{}},
-		}
-		if err := c.run(cmd, args); err != nil {
-			logger.Failuref(err.Error())
-		}
+	RunE: getSourceAllCmdRun,
+}
 
-		c = getCommand{
-			apiType: gitRepositoryType,
-			list:    &gitRepositoryListAdapter{&sourcev1.GitRepositoryList{}},
-		}
-		if err := c.run(cmd, args); err != nil {
-			logger.Failuref(err.Error())
-		}
+func init() {
+	getSourceCmd.AddCommand(getSourceAllCmd)
+}
 
-		c = getCommand{
-			apiType: helmRepositoryType,
-			list:    &helmRepositoryListAdapter{&sourcev1.HelmRepositoryList{}},
-		}
-		if err := c.run(cmd, args); err != nil {
+func getSourceAllCmdRun(cmd *cobra.Command, args []string) error {
+	return kindsTable{
+		description: "sources",
+		commands: []getCommand{
+			{
+				apiType: bucketType,
+				list:    &bucketListAdapter{&sourcev1.BucketList{}},
+			},
+			{
+				apiType: gitRepositoryType,
+				list:    &gitRepositoryListAdapter{&sourcev1.GitRepositoryList{}},
+			},
+			{
+				apiType: helmRepositoryType,
+				list:    &helmRepositoryListAdapter{&sourcev1.HelmRepositoryList{}},
+			},
+			{
+				apiType: helmChartType,
+				list:    &helmChartListAdapter{&sourcev1.HelmChartList{}},
+			},
+		},
+		// the sources share the same columns
+		columns: func(c getCommand, i int) ([]string, []string) {
+			return c.list.headers(getArgs.allNamespaces, false), c.list.summariseItem(i, getArgs.allNamespaces, false, false)
+		},
+	}.run(args)
+}
+
+// validateGetAll checks the flags that can't be used when listing the
+// objects of several kinds.
+func validateGetAll() error {
+	if getArgs.watch {
+		return fmt.Errorf("watching is not supported when listing all kinds")
+	}
+	if getArgs.wait {
+		return fmt.Errorf("waiting is not supported when listing all kinds")
+	}
+	if structuredOutput() {
+		return fmt.Errorf("%s output is not supported when listing all kinds", getArgs.output)
+	}
+	if err := validateListLimit(true); err != nil {
+		return err
+	}
+	return validateGroupByNamespace()
+}
+
+// kindsTable lists the objects of several kinds in a single table, with
+// a kind column before the name column.
+type kindsTable struct {
+	// description names the objects in the messages, e.g. "sources"
+	description string
+	commands    []getCommand
+	// columns returns the header and the row of the i-th object listed
+	// by the command, the header must be the same for all the kinds
+	columns func(c getCommand, i int) ([]string, []string)
+}
+
+func (t kindsTable) run(args []string) error {
+	if err := validateGetAll(); err != nil {
+		return err
+	}
+	if len(splitContexts(rootArgs.kubecontext)) > 1 {
+		return fmt.Errorf("multiple contexts are not supported when listing all %s, use the kind specific commands instead", t.description)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	// the wide columns differ between the kinds
+	if getArgs.output == "wide" {
+		logger.Warningf("wide output is not supported when listing all %s, use the kind specific commands instead", t.description)
+	}
+
+	var header []string
+	var rows []kindRow
+	for _, c := range t.commands {
+		if err := c.listObjects(ctx, kubeClient, args); err != nil {
 			logger.Failuref(err.Error())
+			continue
 		}
-
-		c = getCommand{
-			apiType: helmChartType,
-			list:    &helmChartListAdapter{&sourcev1.HelmChartList{}},
+		items, err := apimeta.ExtractList(c.list.asClientList())
+		if err != nil {
+			return err
 		}
-		if err := c.run(cmd, args); err != nil {
-			logger.Failuref(err.Error())
+		printContinueToken(c.list.asClientList(), c.kind)
+		for i, item := range items {
+			obj := item.(client.Object)
+			kindHeader, row := t.columns(c, i)
+			header = append(insertKindColumn(kindHeader, "Kind"), objectHeaders()...)
+			if len(filterStatusRows(kindHeader, [][]string{row}, getArgs.statusSelector)) == 0 {
+				continue
+			}
+			rows = append(rows, kindRow{
+				object:  obj,
+				columns: append(insertKindColumn(row, c.kind), objectColumns(obj)...),
+			})
 		}
+	}
 
+	if len(rows) == 0 {
+		logger.Failuref("no %s found in %s namespace", t.description, rootArgs.namespace)
 		return nil
-	},
+	}
+
+	table := sortKindRows(rows)
+	printGetTable(os.Stdout, header, table)
+	if getArgs.failOnNotReady {
+		return notReadyError(countNotReady(header, table), len(table))
+	}
+	return nil
 }
 
-func init() {
-	getSourceCmd.AddCommand(getSourceAllCmd)
+// kindRow is a row of a table that merges the objects of several kinds.
+type kindRow struct {
+	object  client.Object
+	columns []string
+}
+
+// sortKindRows sorts the rows by namespace and name, keeping the
+// order of the kinds for objects with the same name, unless another
+// order is requested with --sort-by or --reverse.
+func sortKindRows(rows []kindRow) [][]string {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].object, rows[j].object
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+
+	var table [][]string
+	var objects []client.Object
+	for _, row := range rows {
+		table = append(table, row.columns)
+		objects = append(objects, row.object)
+	}
+	sortObjectRows(objects, table)
+	return table
+}
+
+// insertKindColumn inserts the kind before the name column, which
+// comes after the namespace column when listing all namespaces.
+func insertKindColumn(columns []string, kind string) []string {
+	i := 0
+	if getArgs.allNamespaces {
+		i = 1
+	}
+	result := append([]string{}, columns[:i]...)
+	result = append(result, kind)
+	return append(result, columns[i:]...)
 }
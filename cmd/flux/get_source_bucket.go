@@ -44,19 +44,26 @@ func init() {
 	getSourceCmd.AddCommand(getSourceBucketCmd)
 }
 
-func (a *bucketListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool) []string {
+func (a *bucketListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
 	item := a.Items[i]
 	var revision string
 	if item.GetArtifact() != nil {
 		revision = item.GetArtifact().Revision
 	}
 	status, msg := statusAndMessage(item.Status.Conditions)
-	return append(nameColumns(&item, includeNamespace, includeKind),
+	row := append(nameColumns(&item, includeNamespace, includeKind),
 		status, msg, revision, strings.Title(strconv.FormatBool(item.Spec.Suspend)))
+	if wide {
+		row = append(row, item.Spec.Endpoint, item.Spec.BucketName)
+	}
+	return row
 }
 
-func (a bucketListAdapter) headers(includeNamespace bool) []string {
+func (a bucketListAdapter) headers(includeNamespace bool, wide bool) []string {
 	headers := []string{"Name", "Ready", "Message", "Revision", "Suspended"}
+	if wide {
+		headers = append(headers, "Endpoint", "Bucket")
+	}
 	if includeNamespace {
 		headers = append([]string{"Namespace"}, headers...)
 	}
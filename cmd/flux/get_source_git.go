@@ -19,6 +19,7 @@ package main
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/spf13/cobra"
@@ -33,6 +34,12 @@ var getSourceGitCmd = &cobra.Command{
 
  # List Git repositories from all namespaces
   flux get sources git --all-namespaces
+
+  # List Git repositories that do not belong to a set of teams
+  flux get sources git -l 'team notin (payments, search)'
+
+  # List Git repositories with their URL, ref and fetched commit
+  flux get sources git -o wide
 `,
 	RunE: getCommand{
 		apiType: gitRepositoryType,
@@ -44,21 +51,65 @@ func init() {
 	getSourceCmd.AddCommand(getSourceGitCmd)
 }
 
-func (a *gitRepositoryListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool) []string {
+func (a *gitRepositoryListAdapter) summariseItem(i int, includeNamespace bool, includeKind bool, wide bool) []string {
 	item := a.Items[i]
 	var revision string
 	if item.GetArtifact() != nil {
 		revision = item.GetArtifact().Revision
 	}
 	status, msg := statusAndMessage(item.Status.Conditions)
-	return append(nameColumns(&item, includeNamespace, includeKind),
+	row := append(nameColumns(&item, includeNamespace, includeKind),
 		status, msg, revision, strings.Title(strconv.FormatBool(item.Spec.Suspend)))
+	if wide {
+		row = append(row, item.Spec.URL, gitRepositoryRef(item.Spec.Reference))
+		ref, commit, updated := "-", "-", "-"
+		if artifact := item.GetArtifact(); artifact != nil {
+			ref, commit = splitGitRevision(artifact.Revision)
+			updated = artifact.LastUpdateTime.Time.Format(time.RFC3339)
+		}
+		row = append(row, ref, commit, updated)
+	}
+	return row
 }
 
-func (a gitRepositoryListAdapter) headers(includeNamespace bool) []string {
+func (a gitRepositoryListAdapter) headers(includeNamespace bool, wide bool) []string {
 	headers := []string{"Name", "Ready", "Message", "Revision", "Suspended"}
+	if wide {
+		headers = append(headers, "URL", "Ref", "Fetched ref", "Commit", "Last updated")
+	}
 	if includeNamespace {
 		headers = append([]string{"Namespace"}, headers...)
 	}
 	return headers
 }
+
+// gitRepositoryRef returns the Git reference in the same order of
+// precedence as the source-controller, e.g. 'branch/main'.
+func gitRepositoryRef(ref *sourcev1.GitRepositoryRef) string {
+	switch {
+	case ref == nil:
+		return ""
+	case ref.Commit != "":
+		return "commit/" + ref.Commit
+	case ref.SemVer != "":
+		return "semver/" + ref.SemVer
+	case ref.Tag != "":
+		return "tag/" + ref.Tag
+	case ref.Branch != "":
+		return "branch/" + ref.Branch
+	}
+	return ""
+}
+
+// splitGitRevision splits a GitRepository artifact revision, e.g.
+// 'main/<sha>', into the branch or tag and the short commit SHA.
+func splitGitRevision(revision string) (string, string) {
+	ref, commit := "-", revision
+	if i := strings.LastIndex(revision, "/"); i >= 0 {
+		ref, commit = revision[:i], revision[i+1:]
+	}
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	return ref, commit
+}
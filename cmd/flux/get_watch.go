@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the escape sequences used to redraw the table in a terminal
+const (
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+	highlightRow   = "\x1b[7m%s\x1b[0m\n"
+)
+
+// watchTable renders the table every poll interval until interrupted.
+// On a terminal, the table is redrawn on the alternate screen and the
+// rows whose Ready column changed since the previous render are
+// highlighted. Otherwise, the table is appended to the output each
+// time its content changes.
+func watchTable(table func() ([]string, [][]string, error)) error {
+	interactive := terminal.IsTerminal(int(os.Stdout.Fd()))
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	if interactive {
+		fmt.Print(enterAltScreen)
+		defer fmt.Print(exitAltScreen)
+	}
+
+	ticker := time.NewTicker(getArgs.pollInterval)
+	defer ticker.Stop()
+
+	var previous [][]string
+	for {
+		header, rows, err := table()
+		if err != nil {
+			return err
+		}
+		// multi-line messages would break the row to line mapping
+		for _, row := range rows {
+			for i := range row {
+				row[i] = strings.ReplaceAll(row[i], "\n", " ")
+			}
+		}
+
+		switch {
+		case interactive:
+			fmt.Print(clearScreen)
+			fmt.Printf("Every %s: %s\n\n", getArgs.pollInterval, time.Now().Format(time.RFC3339))
+			printWatchTable(header, rows, readyChanged(header, previous, rows))
+		case !reflect.DeepEqual(withoutAge(header, previous), withoutAge(header, rows)):
+			printTable(os.Stdout, header, rows)
+			fmt.Println()
+		}
+		previous = rows
+
+		select {
+		case <-interrupt:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// readyChanged returns the indexes of the rows whose Ready column
+// differs from the previous render, the rows being identified by the
+// columns that come before the Ready column.
+func readyChanged(header []string, previous, rows [][]string) map[int]bool {
+	ready := -1
+	for i, h := range header {
+		if h == "Ready" {
+			ready = i
+		}
+	}
+	changed := make(map[int]bool)
+	if ready < 0 || previous == nil {
+		return changed
+	}
+
+	key := func(row []string) string {
+		return strings.Join(row[:ready], "/")
+	}
+	status := make(map[string]string)
+	for _, row := range previous {
+		status[key(row)] = row[ready]
+	}
+	for i, row := range rows {
+		if s, ok := status[key(row)]; ok && s != row[ready] {
+			changed[i] = true
+		}
+	}
+	return changed
+}
+
+// withoutAge returns the rows with their age column blanked, for the
+// table not to be printed again when only the ages changed.
+func withoutAge(header []string, rows [][]string) [][]string {
+	column := -1
+	for i, h := range header {
+		if h == ageHeader {
+			column = i
+		}
+	}
+	if column < 0 || rows == nil {
+		return rows
+	}
+	result := make([][]string, len(rows))
+	for i, row := range rows {
+		result[i] = append([]string{}, row...)
+		result[i][column] = ""
+	}
+	return result
+}
+
+func printWatchTable(header []string, rows [][]string, highlight map[int]bool) {
+	var buf bytes.Buffer
+	printTable(&buf, header, rows)
+	offset := 1
+	if getArgs.noHeaders {
+		offset = 0
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		// the first line is the header, unless omitted
+		if highlight[i-offset] {
+			fmt.Printf(highlightRow, line)
+			continue
+		}
+		fmt.Println(line)
+	}
+}
+
+// waitForReady polls the named object every poll interval until its
+// Ready condition is True, printing the changes of the condition in
+// between. A False condition is not final, as the controller retries
+// failed reconciliations, so it fails only when the object is still not
+// ready after the timeout, with the last observed status.
+func (get getCommand) waitForReady(kubeClient client.Client, name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(getArgs.pollInterval)
+	defer ticker.Stop()
+
+	last := waitingMessage
+	logger.Waitingf("waiting for %s %s to be ready", get.kind, name)
+	for {
+		err := get.listObjects(ctx, kubeClient, []string{name})
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if err == nil {
+			items, err := apimeta.ExtractList(get.list.asClientList())
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return fmt.Errorf("%s object '%s' not found in %s namespace", get.kind, name, rootArgs.namespace)
+			}
+
+			if c := readyCondition(items[0].(client.Object)); c != nil {
+				if c.Status == metav1.ConditionTrue {
+					logger.Successf("%s is ready: %s", name, c.Message)
+					return nil
+				}
+				if status := fmt.Sprintf("%s: %s", c.Reason, c.Message); status != last {
+					logger.Waitingf("%s %s", name, status)
+					last = status
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for %s to be ready, last observed status: %s", name, last)
+		case <-ticker.C:
+		}
+	}
+}
@@ -52,12 +52,17 @@ If a previous version is installed, then an in-place upgrade will be performed.`
 
   # Write install manifests to file
   flux install --export > flux-system.yaml
+
+  # Write install manifests to a tar archive, with a file per component
+  flux install --components-extra=image-reflector-controller,image-automation-controller \
+    --export-manifests-tar=flux-system.tar
 `,
 	RunE: installCmdRun,
 }
 
 type installFlags struct {
 	export             bool
+	exportTar          string
 	dryRun             bool
 	version            string
 	defaultComponents  []string
@@ -80,6 +85,8 @@ var installArgs = NewInstallFlags()
 func init() {
 	installCmd.Flags().BoolVar(&installArgs.export, "export", false,
 		"write the install manifests to stdout and exit")
+	installCmd.Flags().StringVar(&installArgs.exportTar, "export-manifests-tar", "",
+		"write the install manifests to a tar archive with a file per component and a kustomization.yaml, and exit")
 	installCmd.Flags().BoolVarP(&installArgs.dryRun, "dry-run", "", false,
 		"only print the object that would be applied")
 	installCmd.Flags().StringVarP(&installArgs.version, "version", "v", "",
@@ -123,12 +130,20 @@ func installCmdRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := utils.ValidateRegistry(installArgs.registry); err != nil {
+		return err
+	}
+
 	if ver, err := getVersion(installArgs.version); err != nil {
 		return err
 	} else {
 		installArgs.version = ver
 	}
 
+	if installArgs.export && installArgs.exportTar != "" {
+		return fmt.Errorf("--export and --export-manifests-tar are mutually exclusive")
+	}
+
 	if !installArgs.export {
 		logger.Generatef("generating manifests")
 	}
@@ -177,6 +192,14 @@ func installCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("install failed: %w", err)
 	}
 
+	if installArgs.exportTar != "" {
+		if err := writeManifestsTar(installArgs.exportTar, manifest, components); err != nil {
+			return fmt.Errorf("install failed: %w", err)
+		}
+		logger.Successf("manifests written to %s", installArgs.exportTar)
+		return nil
+	}
+
 	if rootArgs.verbose {
 		fmt.Print(manifest.Content)
 	} else if installArgs.export {
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fluxcd/flux2/pkg/manifestgen"
+)
+
+// writeManifestsTar writes the install manifests to a tar archive, with a
+// file per component and a kustomization.yaml listing them, for the
+// manifests to be committed and reconciled from a repository.
+func writeManifestsTar(filename string, manifest *manifestgen.Manifest, components []string) error {
+	files, err := splitManifests(manifest.Content, components)
+	if err != nil {
+		return err
+	}
+
+	// the files are listed in the order the resources are applied
+	order := append([]string{"namespace"}, components...)
+	order = append(order, "rbac", "policies", "other")
+	var resources []string
+	for _, name := range order {
+		if _, ok := files[name]; ok {
+			resources = append(resources, name+".yaml")
+		}
+	}
+	kustomization, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("writing archive failed: %w", err)
+	}
+	defer file.Close()
+
+	tw := tar.NewWriter(file)
+	write := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write("kustomization.yaml", kustomization); err != nil {
+		return fmt.Errorf("writing archive failed: %w", err)
+	}
+	for _, resource := range resources {
+		if err := write(resource, files[strings.TrimSuffix(resource, ".yaml")].Bytes()); err != nil {
+			return fmt.Errorf("writing archive failed: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("writing archive failed: %w", err)
+	}
+	return file.Sync()
+}
+
+// splitManifests groups the objects of the multi-doc YAML by the component
+// they belong to. The objects shared by the components are grouped by kind.
+func splitManifests(content string, components []string) (map[string]*bytes.Buffer, error) {
+	files := map[string]*bytes.Buffer{}
+	decoder := k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(content), 2048)
+	for {
+		var obj unstructured.Unstructured
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("parsing manifests failed: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		name := manifestComponent(&obj, components)
+		if files[name] == nil {
+			files[name] = &bytes.Buffer{}
+		}
+		fmt.Fprintf(files[name], "---\n%s", data)
+	}
+	return files, nil
+}
+
+// manifestComponent returns the name of the file the object is written to.
+func manifestComponent(obj *unstructured.Unstructured, components []string) string {
+	switch obj.GetKind() {
+	case "Namespace":
+		return "namespace"
+	case "CustomResourceDefinition":
+		for _, component := range components {
+			for _, crd := range componentCRDs[component] {
+				if obj.GetName() == crd {
+					return component
+				}
+			}
+		}
+	case "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding":
+		return "rbac"
+	case "NetworkPolicy":
+		return "policies"
+	}
+
+	// the controllers are named after their component, as their
+	// accounts and services, or are selected by the services
+	app, _, _ := unstructured.NestedString(obj.Object, "spec", "selector", "app")
+	for _, component := range components {
+		if obj.GetName() == component || app == component {
+			return component
+		}
+	}
+	return "other"
+}
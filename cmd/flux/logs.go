@@ -26,6 +26,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
@@ -50,6 +51,15 @@ var logsCmd = &cobra.Command{
 	# Filter logs by kind, name and namespace
 	flux logs --kind=Kustomization --name=podinfo --namespace=default
 
+	# Print the logs of the last 30 minutes
+	flux logs --since=30m --all-namespaces
+
+	# Print the logs since the start of an incident
+	flux logs --since-time=2021-03-01T10:00:00Z --all-namespaces
+
+	# Interleave the logs of the source and kustomize controllers
+	flux logs --controllers=source,kustomize --follow --all-namespaces
+
 	# Print logs when Flux is installed in a different namespace than flux-system
 	flux logs --flux-namespace=my-namespace
     `,
@@ -60,10 +70,13 @@ type logsFlags struct {
 	logLevel      flags.LogLevel
 	follow        bool
 	tail          int64
+	since         time.Duration
+	sinceTime     string
 	kind          string
 	name          string
 	fluxNamespace string
 	allNamespaces bool
+	controllers   []string
 }
 
 var logsArgs = &logsFlags{
@@ -72,12 +85,16 @@ var logsArgs = &logsFlags{
 
 func init() {
 	logsCmd.Flags().Var(&logsArgs.logLevel, "level", logsArgs.logLevel.Description())
-	logsCmd.Flags().StringVarP(&logsArgs.kind, "kind", "", logsArgs.kind, "displays errors of a particular toolkit kind e.g GitRepository")
+	logsCmd.Flags().StringVarP(&logsArgs.kind, "kind", "", logsArgs.kind, "displays the logs of a particular toolkit kind e.g GitRepository")
 	logsCmd.Flags().StringVarP(&logsArgs.name, "name", "", logsArgs.name, "specifies the name of the object logs to be displayed")
 	logsCmd.Flags().BoolVarP(&logsArgs.follow, "follow", "f", logsArgs.follow, "specifies if the logs should be streamed")
 	logsCmd.Flags().Int64VarP(&logsArgs.tail, "tail", "", logsArgs.tail, "lines of recent log file to display")
+	logsCmd.Flags().DurationVar(&logsArgs.since, "since", logsArgs.since, "only display the logs newer than a relative duration like 30m or 2h")
+	logsCmd.Flags().StringVar(&logsArgs.sinceTime, "since-time", logsArgs.sinceTime, "only display the logs after a RFC3339 timestamp like 2021-03-01T10:00:00Z")
 	logsCmd.Flags().StringVarP(&logsArgs.fluxNamespace, "flux-namespace", "", rootArgs.defaults.Namespace, "the namespace where the Flux components are running")
 	logsCmd.Flags().BoolVarP(&logsArgs.allNamespaces, "all-namespaces", "A", false, "displays logs for objects across all namespaces")
+	logsCmd.Flags().StringSliceVar(&logsArgs.controllers, "controllers", nil,
+		"interleave the logs of the given controllers by timestamp, accepts a comma separated list e.g. source,kustomize or 'all'")
 	rootCmd.AddCommand(logsCmd)
 }
 
@@ -102,9 +119,11 @@ func logsCmdRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no argument required")
 	}
 
-	pods, err = getPods(ctx, clientset, fluxSelector)
-	if err != nil {
-		return err
+	if logsArgs.since != 0 && logsArgs.sinceTime != "" {
+		return fmt.Errorf("only one of --since and --since-time can be set")
+	}
+	if logsArgs.since < 0 {
+		return fmt.Errorf("since must not be negative")
 	}
 
 	logOpts := &corev1.PodLogOptions{
@@ -115,6 +134,40 @@ func logsCmdRun(cmd *cobra.Command, args []string) error {
 		logOpts.TailLines = &logsArgs.tail
 	}
 
+	if logsArgs.since > 0 {
+		seconds := int64(logsArgs.since.Seconds())
+		logOpts.SinceSeconds = &seconds
+	}
+
+	if logsArgs.sinceTime != "" {
+		t, err := time.Parse(time.RFC3339, logsArgs.sinceTime)
+		if err != nil {
+			return fmt.Errorf("invalid since time '%s', must be a RFC3339 timestamp", logsArgs.sinceTime)
+		}
+		sinceTime := metav1.NewTime(t)
+		logOpts.SinceTime = &sinceTime
+	}
+
+	if len(logsArgs.controllers) > 0 {
+		controllerPods, err := getControllerPods(ctx, clientset, fluxSelector, logsArgs.controllers)
+		if err != nil {
+			return err
+		}
+		var requests []controllerLogRequest
+		for _, cp := range controllerPods {
+			requests = append(requests, controllerLogRequest{
+				controller: cp.controller,
+				request:    clientset.CoreV1().Pods(logsArgs.fluxNamespace).GetLogs(cp.pod.Name, logOpts),
+			})
+		}
+		return interleavedPodLogs(ctx, requests, controllerLogsWindow)
+	}
+
+	pods, err = getPods(ctx, clientset, fluxSelector)
+	if err != nil {
+		return err
+	}
+
 	var requests []rest.ResponseWrapper
 	for _, pod := range pods {
 		req := clientset.CoreV1().Pods(logsArgs.fluxNamespace).GetLogs(pod.Name, logOpts)
@@ -202,20 +255,28 @@ func createLabelStringFromMap(m map[string]string) string {
 }
 
 func logRequest(mu *sync.Mutex, ctx context.Context, request rest.ResponseWrapper, w io.Writer) error {
-	stream, err := request.Stream(ctx)
+	t, err := template.New("log").Parse(controllerLogTemplate)
 	if err != nil {
-		return err
+		return fmt.Errorf("unable to create template, err: %s", err)
 	}
-	defer stream.Close()
 
-	scanner := bufio.NewScanner(stream)
+	return scanLogEntries(ctx, request, func(l *ControllerLogEntry) {
+		mu.Lock()
+		filterPrintLog(t, l)
+		mu.Unlock()
+	})
+}
 
-	const logTmpl = "{{.Timestamp}} {{.Level}} {{.Kind}}{{if .Name}}/{{.Name}}.{{.Namespace}}{{end}} - {{.Message}} {{.Error}}\n"
-	t, err := template.New("log").Parse(logTmpl)
+// scanLogEntries streams the logs of the request and calls fn for each
+// JSON formatted entry.
+func scanLogEntries(ctx context.Context, request rest.ResponseWrapper, fn func(l *ControllerLogEntry)) error {
+	stream, err := request.Stream(ctx)
 	if err != nil {
-		return fmt.Errorf("unable to create template, err: %s", err)
+		return err
 	}
+	defer stream.Close()
 
+	scanner := bufio.NewScanner(stream)
 	for scanner.Scan() {
 		line := scanner.Text()
 		if !strings.HasPrefix(line, "{") {
@@ -224,12 +285,10 @@ func logRequest(mu *sync.Mutex, ctx context.Context, request rest.ResponseWrappe
 		var l ControllerLogEntry
 		if err := json.Unmarshal([]byte(line), &l); err != nil {
 			logger.Failuref("parse error: %s", err)
-			break
+			continue
 		}
-
-		mu.Lock()
-		filterPrintLog(t, &l)
-		mu.Unlock()
+		l.normalize([]byte(line))
+		fn(&l)
 	}
 
 	return nil
@@ -249,6 +308,9 @@ func filterPrintLog(t *template.Template, l *ControllerLogEntry) {
 	}
 }
 
+// controllerLogTemplate formats a ControllerLogEntry as a line.
+const controllerLogTemplate = "{{if .Controller}}[{{.Controller}}] {{end}}{{.Timestamp}} {{.Level}} {{.Kind}}{{if .Name}}/{{.Name}}.{{.Namespace}}{{end}} - {{.Message}} {{.Error}}\n"
+
 type ControllerLogEntry struct {
 	Timestamp string         `json:"ts"`
 	Level     flags.LogLevel `json:"level"`
@@ -258,4 +320,39 @@ type ControllerLogEntry struct {
 	Kind      string         `json:"reconciler kind,omitempty"`
 	Name      string         `json:"name,omitempty"`
 	Namespace string         `json:"namespace,omitempty"`
+
+	ControllerKind string `json:"controllerKind,omitempty"`
+
+	// Controller is the short name of the controller which logged the
+	// entry, it is only set when interleaving the logs of several controllers.
+	Controller string `json:"-"`
+}
+
+// normalize fills in the kind, name and namespace from the keys used by
+// newer controller versions, which log the kind as controllerKind and
+// the reconciled object under its kind, e.g. "Kustomization": {"name": "podinfo", "namespace": "default"}.
+func (l *ControllerLogEntry) normalize(line []byte) {
+	if l.Kind == "" {
+		l.Kind = l.ControllerKind
+	}
+	if l.Kind == "" || l.Name != "" {
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return
+	}
+	raw, ok := fields[l.Kind]
+	if !ok {
+		return
+	}
+	var ref struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	}
+	if err := json.Unmarshal(raw, &ref); err == nil {
+		l.Name = ref.Name
+		l.Namespace = ref.Namespace
+	}
 }
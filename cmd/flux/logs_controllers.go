@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// controllerLogsWindow is how long the log entries are held back before
+// being printed, so that the entries of different controllers which are
+// received slightly out of order can be sorted by their timestamp.
+const controllerLogsWindow = time.Second
+
+type controllerPod struct {
+	controller string
+	pod        corev1.Pod
+}
+
+type controllerLogRequest struct {
+	controller string
+	request    rest.ResponseWrapper
+}
+
+// controllerLogLine is a log entry with its parsed timestamp and the
+// time it was received at.
+type controllerLogLine struct {
+	entry     *ControllerLogEntry
+	timestamp time.Time
+	received  time.Time
+}
+
+func newControllerLogLine(entry *ControllerLogEntry, received time.Time) controllerLogLine {
+	ts, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+	if err != nil {
+		ts = received
+	}
+	return controllerLogLine{
+		entry:     entry,
+		timestamp: ts,
+		received:  received,
+	}
+}
+
+// controllerShortName returns the name of a controller without the
+// -controller suffix, e.g. source for source-controller.
+func controllerShortName(name string) string {
+	return strings.TrimSuffix(name, "-controller")
+}
+
+// getControllerPods returns the pods of the given controllers, which
+// are referred to by their short or full name, or 'all'.
+func getControllerPods(ctx context.Context, c *kubernetes.Clientset, label string, controllers []string) ([]controllerPod, error) {
+	opts := metav1.ListOptions{
+		LabelSelector: label,
+	}
+	deployList, err := c.AppsV1().Deployments(logsArgs.fluxNamespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := make(map[string]appsv1.Deployment)
+	var names []string
+	for _, deploy := range deployList.Items {
+		name := controllerShortName(deploy.Name)
+		deployments[name] = deploy
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var selected []string
+	seen := make(map[string]bool)
+	for _, controller := range controllers {
+		if controller == "all" {
+			selected = names
+			break
+		}
+		name := controllerShortName(controller)
+		if _, ok := deployments[name]; !ok {
+			return nil, fmt.Errorf("unsupported controller '%s', must be one of: %s",
+				controller, strings.Join(append(names, "all"), ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, name)
+		}
+	}
+
+	var ret []controllerPod
+	for _, name := range selected {
+		opts := metav1.ListOptions{
+			LabelSelector: createLabelStringFromMap(deployments[name].Spec.Template.Labels),
+		}
+		podList, err := c.CoreV1().Pods(logsArgs.fluxNamespace).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range podList.Items {
+			ret = append(ret, controllerPod{controller: name, pod: pod})
+		}
+	}
+
+	return ret, nil
+}
+
+// interleavedPodLogs streams the logs of all the requests at once, and
+// prints the entries ordered by their timestamp, prefixed with the name
+// of the controller.
+func interleavedPodLogs(ctx context.Context, requests []controllerLogRequest, window time.Duration) error {
+	t, err := template.New("log").Parse(controllerLogTemplate)
+	if err != nil {
+		return fmt.Errorf("unable to create template, err: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines := make(chan controllerLogLine)
+	errs := make(chan error, len(requests))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(requests))
+
+	for _, request := range requests {
+		go func(r controllerLogRequest) {
+			defer wg.Done()
+			err := scanLogEntries(ctx, r.request, func(l *ControllerLogEntry) {
+				l.Controller = r.controller
+				lines <- newControllerLogLine(l, time.Now())
+			})
+			if err != nil {
+				errs <- err
+				cancel()
+			}
+		}(request)
+	}
+
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	mergeLogLines(lines, window, func(l *ControllerLogEntry) {
+		filterPrintLog(t, l)
+	})
+
+	close(errs)
+	return <-errs
+}
+
+// mergeLogLines calls print for the lines ordered by their timestamp.
+// A line is held back until it has been buffered for the duration of
+// the window, the remaining lines are flushed once lines is closed.
+func mergeLogLines(lines <-chan controllerLogLine, window time.Duration, print func(l *ControllerLogEntry)) {
+	var buffer []controllerLogLine
+	flush := func(until time.Time) {
+		sort.SliceStable(buffer, func(i, j int) bool {
+			return buffer[i].timestamp.Before(buffer[j].timestamp)
+		})
+		n := 0
+		for n < len(buffer) && !buffer[n].received.After(until) {
+			print(buffer[n].entry)
+			n++
+		}
+		buffer = buffer[n:]
+	}
+
+	ticker := time.NewTicker(window / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				flush(time.Now())
+				return
+			}
+			buffer = append(buffer, line)
+		case now := <-ticker.C:
+			flush(now.Add(-window))
+		}
+	}
+}
@@ -17,14 +17,19 @@ limitations under the License.
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"regexp"
 	"time"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"github.com/fluxcd/flux2/internal/utils"
 	"github.com/fluxcd/flux2/pkg/manifestgen/install"
 )
 
@@ -36,7 +41,13 @@ var rootCmd = &cobra.Command{
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Short:         "Command line utility for assembling Kubernetes CD pipelines",
-	Long:          `Command line utility for assembling Kubernetes CD pipelines the GitOps way.`,
+	Long: `Command line utility for assembling Kubernetes CD pipelines the GitOps way.
+The namespace scope is given with --namespace, or else is the namespace of the kubeconfig context if set,
+or else flux-system. The commands managing the Flux installation default to flux-system.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		setContextNamespace(cmd)
+		return nil
+	},
 	Example: `  # Check prerequisites
   flux check --pre
 
@@ -107,12 +118,16 @@ type rootFlags struct {
 var rootArgs = NewRootFlags()
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&rootArgs.namespace, "namespace", "n", rootArgs.defaults.Namespace, "the namespace scope for this operation")
+	rootCmd.PersistentFlags().StringVarP(&rootArgs.namespace, "namespace", "n", rootArgs.defaults.Namespace, "the namespace scope for this operation, defaults to the namespace of the kubeconfig context if set")
 	rootCmd.PersistentFlags().DurationVar(&rootArgs.timeout, "timeout", 5*time.Minute, "timeout for this operation")
-	rootCmd.PersistentFlags().BoolVar(&rootArgs.verbose, "verbose", false, "print generated objects")
+	rootCmd.PersistentFlags().BoolVar(&rootArgs.verbose, "verbose", false, "print generated objects, and the details of the Kubernetes API errors on failure")
 	rootCmd.PersistentFlags().StringVarP(&rootArgs.kubeconfig, "kubeconfig", "", "",
-		"absolute path to the kubeconfig file")
-	rootCmd.PersistentFlags().StringVarP(&rootArgs.kubecontext, "context", "", "", "kubernetes context to use")
+		"path to the kubeconfig file, or a list of files to merge, defaults to the KUBECONFIG files or ~/.kube/config")
+	rootCmd.PersistentFlags().StringVarP(&rootArgs.kubecontext, "context", "", "",
+		"kubernetes context to use, the get commands accept a comma-separated list of contexts")
+	rootCmd.PersistentFlags().IntVar(&utils.KubeConnectRetries, "connect-retries", utils.KubeConnectRetries,
+		"number of times to retry connecting to the cluster on network errors")
+	rootCmd.PersistentFlags().MarkHidden("connect-retries")
 
 	rootCmd.DisableAutoGenTag = true
 }
@@ -128,28 +143,107 @@ func NewRootFlags() rootFlags {
 
 func main() {
 	log.SetFlags(0)
-	configureKubeconfig()
 	if err := rootCmd.Execute(); err != nil {
 		logger.Failuref("%v", err)
+		if rootArgs.verbose {
+			printErrorDetails(os.Stderr, err)
+		}
+		if err, ok := err.(*RequestError); ok {
+			os.Exit(err.StatusCode)
+		}
 		os.Exit(1)
 	}
 }
 
-func configureKubeconfig() {
-	switch {
-	case len(rootArgs.kubeconfig) > 0:
-	case len(os.Getenv("KUBECONFIG")) > 0:
-		rootArgs.kubeconfig = os.Getenv("KUBECONFIG")
-	default:
-		if home := homeDir(); len(home) > 0 {
-			rootArgs.kubeconfig = filepath.Join(home, ".kube", "config")
+// setContextNamespace sets the namespace scope to the namespace of the
+// kubeconfig context, unless --namespace is given or the command manages
+// the Flux installation.
+func setContextNamespace(cmd *cobra.Command) {
+	if cmd.Flags().Changed("namespace") {
+		return
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c {
+		case installCmd, bootstrapCmd, uninstallCmd, checkCmd, versionCmd, logsCmd:
+			return
 		}
 	}
+
+	var kubecontext string
+	if contexts := splitContexts(rootArgs.kubecontext); len(contexts) > 0 {
+		kubecontext = contexts[0]
+	}
+	if namespace, err := utils.KubeNamespace(rootArgs.kubeconfig, kubecontext); err == nil && namespace != "" {
+		rootArgs.namespace = namespace
+	}
+}
+
+// RequestError is returned by commands that need to exit with a
+// specific status code.
+type RequestError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
 }
 
-func homeDir() string {
-	if h := os.Getenv("HOME"); h != "" {
-		return h
+// forbiddenMessage matches the message of the Forbidden errors, which
+// tells the verb, resource, API group and namespace that are denied.
+var forbiddenMessage = regexp.MustCompile(`cannot (\S+) resource "([^"]*)" in API group "([^"]*)"(?: in the namespace "([^"]*)")?`)
+
+// printErrorDetails prints the object, API and reason of a Kubernetes
+// API error found in the error chain, with a hint of the RBAC
+// permission needed for Forbidden errors.
+func printErrorDetails(w io.Writer, err error) {
+	var apiErr apierrors.APIStatus
+	if !errors.As(err, &apiErr) {
+		return
+	}
+	status := apiErr.Status()
+
+	var group, resource, name, namespace, verb string
+	if status.Details != nil {
+		group, resource, name = status.Details.Group, status.Details.Kind, status.Details.Name
+	}
+	namespace = rootArgs.namespace
+	if m := forbiddenMessage.FindStringSubmatch(status.Message); m != nil {
+		verb, resource, group, namespace = m[1], m[2], m[3], m[4]
+	}
+
+	apiVersion := "v1"
+	if versions := utils.NewScheme().PrioritizedVersionsForGroup(group); len(versions) > 0 {
+		apiVersion = versions[0].String()
+	} else if group != "" {
+		apiVersion = group
+	}
+
+	fmt.Fprintln(w, "error details:")
+	fmt.Fprintf(w, "  reason:     %s (%d)\n", status.Reason, status.Code)
+	if resource != "" {
+		fmt.Fprintf(w, "  resource:   %s\n", resource)
+		fmt.Fprintf(w, "  apiVersion: %s\n", apiVersion)
+	}
+	if name != "" {
+		fmt.Fprintf(w, "  name:       %s\n", name)
+	}
+	if namespace != "" {
+		fmt.Fprintf(w, "  namespace:  %s\n", namespace)
+	}
+	if verb != "" {
+		scope := "cluster wide"
+		if namespace != "" {
+			scope = fmt.Sprintf("in the %s namespace", namespace)
+		}
+		if group == "" {
+			group = "core"
+		}
+		fmt.Fprintf(w, "  hint:       the user needs a Role or ClusterRole allowing to '%s' the '%s' resource of the %s API group %s\n",
+			verb, resource, group, scope)
 	}
-	return os.Getenv("USERPROFILE") // windows
 }
@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -36,10 +37,52 @@ import (
 var reconcileCmd = &cobra.Command{
 	Use:   "reconcile",
 	Short: "Reconcile sources and resources",
-	Long:  "The reconcile sub-commands trigger a reconciliation of sources and resources.",
+	Long: `The reconcile sub-commands trigger a reconciliation of sources and resources.
+With --from-file, the objects listed in a YAML or JSON file are reconciled in order,
+each of them being ready before the next one is reconciled, and a summary is printed at the end.
+The entries of the file have a kind, a name and a namespace which defaults to --namespace.`,
+	Example: `  # Reconcile the objects of a promotion plan in order
+  cat > plan.yaml <<EOF
+  - kind: GitRepository
+    name: flux-system
+  - kind: Kustomization
+    name: infrastructure
+  - kind: HelmRelease
+    name: podinfo
+    namespace: apps
+  EOF
+  flux reconcile --from-file=plan.yaml
+
+  # Reconcile independent objects concurrently
+  flux reconcile --from-file=plan.yaml --parallel
+`,
+	RunE: reconcilePlanCmdRun,
 }
 
+type reconcileFlags struct {
+	timeout      time.Duration
+	pollInterval time.Duration
+	fromFile     string
+	parallel     bool
+}
+
+var reconcileArgs reconcileFlags
+
+// maxReconcilePollInterval caps the exponential backoff between the
+// status checks while waiting for a reconciliation.
+const maxReconcilePollInterval = 30 * time.Second
+
 func init() {
+	reconcileCmd.PersistentFlags().DurationVar(&reconcileArgs.timeout, "timeout", 5*time.Minute,
+		"timeout for the reconciliation to complete")
+	reconcileCmd.PersistentFlags().DurationVar(&reconcileArgs.pollInterval, "poll-interval", time.Second,
+		"initial interval between status checks, doubled after each check")
+	reconcileCmd.PersistentFlags().MarkHidden("poll-interval")
+	reconcileCmd.Flags().StringVar(&reconcileArgs.fromFile, "from-file", "",
+		"YAML or JSON file listing the kind, name and namespace of the objects to reconcile in order")
+	reconcileCmd.Flags().BoolVar(&reconcileArgs.parallel, "parallel", false,
+		"reconcile the objects of --from-file concurrently, instead of waiting for each of them in order")
+
 	rootCmd.AddCommand(reconcileCmd)
 }
 
@@ -63,13 +106,20 @@ type reconcilable interface {
 	successMessage() string              // what do you want to tell people when successfully reconciled?
 }
 
+// reconcileReporter is implemented by the objects that report what the
+// reconciliation changed, by comparing them to a copy taken before the
+// reconciliation was requested.
+type reconcileReporter interface {
+	changesMessage(previous client.Object) string
+}
+
 func (reconcile reconcileCommand) run(cmd *cobra.Command, args []string) error {
 	if len(args) < 1 {
 		return fmt.Errorf("%s name is required", reconcile.kind)
 	}
 	name := args[0]
 
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
 	defer cancel()
 
 	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
@@ -81,37 +131,92 @@ func (reconcile reconcileCommand) run(cmd *cobra.Command, args []string) error {
 		Namespace: rootArgs.namespace,
 		Name:      name,
 	}
+	return reconcileObject(ctx, kubeClient, reconcile.kind, namespacedName, reconcile.object)
+}
 
-	err = kubeClient.Get(ctx, namespacedName, reconcile.object.asClientObject())
+// reconcileObject requests the reconciliation of the object and waits
+// for the controller to handle it, failing when the object is
+// suspended or not ready afterwards.
+func reconcileObject(ctx context.Context, kubeClient client.Client, kind string,
+	namespacedName types.NamespacedName, object reconcilable) error {
+	err := kubeClient.Get(ctx, namespacedName, object.asClientObject())
 	if err != nil {
 		return err
 	}
 
-	if reconcile.object.isSuspended() {
+	if object.isSuspended() {
 		return fmt.Errorf("resource is suspended")
 	}
 
-	logger.Actionf("annotating %s %s in %s namespace", reconcile.kind, name, rootArgs.namespace)
-	if err := requestReconciliation(ctx, kubeClient, namespacedName, reconcile.object); err != nil {
+	previous := object.asClientObject().DeepCopyObject().(client.Object)
+
+	logger.Actionf("annotating %s %s in %s namespace", kind, namespacedName.Name, namespacedName.Namespace)
+	if err := requestReconciliation(ctx, kubeClient, namespacedName, object); err != nil {
 		return err
 	}
-	logger.Successf("%s annotated", reconcile.kind)
+	logger.Successf("%s annotated", kind)
 
-	lastHandledReconcileAt := reconcile.object.lastHandledReconcileRequest()
-	logger.Waitingf("waiting for %s reconciliation", reconcile.kind)
-	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
-		reconciliationHandled(ctx, kubeClient, namespacedName, reconcile.object, lastHandledReconcileAt)); err != nil {
+	lastHandledReconcileAt := object.lastHandledReconcileRequest()
+	logger.Waitingf("waiting for %s reconciliation", kind)
+	if err := waitForReconciliation(ctx,
+		reconciliationHandled(ctx, kubeClient, namespacedName, object, lastHandledReconcileAt),
+		object.GetStatusConditions()); err != nil {
 		return err
 	}
-	logger.Successf("%s reconciliation completed", reconcile.kind)
+	logger.Successf("%s reconciliation completed", kind)
 
-	if apimeta.IsStatusConditionFalse(*reconcile.object.GetStatusConditions(), meta.ReadyCondition) {
-		return fmt.Errorf("%s reconciliation failed", reconcile.kind)
+	if apimeta.IsStatusConditionFalse(*object.GetStatusConditions(), meta.ReadyCondition) {
+		return fmt.Errorf("%s reconciliation failed", kind)
+	}
+	logger.Successf(object.successMessage())
+	if reporter, ok := object.(reconcileReporter); ok {
+		logger.Successf(reporter.changesMessage(previous))
 	}
-	logger.Successf(reconcile.object.successMessage())
 	return nil
 }
 
+// errReconcileTimeout is returned when the reconciliation isn't
+// completed within the timeout.
+var errReconcileTimeout = errors.New("timeout waiting for the reconciliation")
+
+// waitForReconciliation polls the condition until it is met, doubling
+// the interval between checks up to maxReconcilePollInterval. On
+// timeout, the error includes the elapsed time and the last observed
+// Ready message from the given conditions.
+func waitForReconciliation(ctx context.Context, condition wait.ConditionFunc, conditions *[]metav1.Condition) error {
+	return waitWithBackoff(ctx, condition, conditions, reconcileArgs.pollInterval, reconcileArgs.timeout)
+}
+
+// waitWithBackoff is waitForReconciliation with the given initial poll
+// interval and timeout.
+func waitWithBackoff(ctx context.Context, condition wait.ConditionFunc, conditions *[]metav1.Condition,
+	interval, timeout time.Duration) error {
+	start := time.Now()
+	for {
+		done, err := condition()
+		if done && err == nil {
+			return nil
+		}
+		elapsed := time.Since(start)
+		if ctx.Err() != nil || elapsed >= timeout {
+			_, msg := statusAndMessage(*conditions)
+			return fmt.Errorf("%w after %s, last observed status: %s",
+				errReconcileTimeout, elapsed.Round(time.Second), msg)
+		}
+		if err != nil {
+			return err
+		}
+
+		if remaining := timeout - elapsed; interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+		if interval *= 2; interval > maxReconcilePollInterval {
+			interval = maxReconcilePollInterval
+		}
+	}
+}
+
 func reconciliationHandled(ctx context.Context, kubeClient client.Client,
 	namespacedName types.NamespacedName, obj reconcilable, lastHandledReconcileAt string) wait.ConditionFunc {
 	return func() (bool, error) {
@@ -26,15 +26,15 @@ import (
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
 
 	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
 )
 
 var reconcileAlertCmd = &cobra.Command{
-	Use:   "alert [name]",
-	Short: "Reconcile an Alert",
-	Long:  `The reconcile alert command triggers a reconciliation of an Alert resource and waits for it to finish.`,
+	Use:               "alert [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.AlertList{}),
+	Short:             "Reconcile an Alert",
+	Long:              `The reconcile alert command triggers a reconciliation of an Alert resource and waits for it to finish.`,
 	Example: `  # Trigger a reconciliation for an existing alert
   flux reconcile alert main
 `,
@@ -51,7 +51,7 @@ func reconcileAlertCmdRun(cmd *cobra.Command, args []string) error {
 	}
 	name := args[0]
 
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
 	defer cancel()
 
 	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
@@ -89,8 +89,8 @@ func reconcileAlertCmdRun(cmd *cobra.Command, args []string) error {
 	logger.Successf("Alert annotated")
 
 	logger.Waitingf("waiting for reconciliation")
-	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
-		isAlertReady(ctx, kubeClient, namespacedName, &alert)); err != nil {
+	if err := waitForReconciliation(ctx,
+		isAlertReady(ctx, kubeClient, namespacedName, &alert), &alert.Status.Conditions); err != nil {
 		return err
 	}
 	logger.Successf("Alert reconciliation completed")
@@ -37,11 +37,13 @@ import (
 )
 
 var reconcileHrCmd = &cobra.Command{
-	Use:     "helmrelease [name]",
-	Aliases: []string{"hr"},
-	Short:   "Reconcile a HelmRelease resource",
+	Use:               "helmrelease [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&helmv2.HelmReleaseList{}),
+	Aliases:           []string{"hr"},
+	Short:             "Reconcile a HelmRelease resource",
 	Long: `
-The reconcile kustomization command triggers a reconciliation of a HelmRelease resource and waits for it to finish.`,
+The reconcile helmrelease command triggers a reconciliation of a HelmRelease resource and waits for it to finish.
+With --with-source, the source of the chart is reconciled first, in the namespace of the source reference if set.`,
 	Example: `  # Trigger a HelmRelease apply outside of the reconciliation interval
   flux reconcile hr podinfo
 
@@ -69,7 +71,7 @@ func reconcileHrCmdRun(cmd *cobra.Command, args []string) error {
 	}
 	name := args[0]
 
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
 	defer cancel()
 
 	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
@@ -114,10 +116,10 @@ func reconcileHrCmdRun(cmd *cobra.Command, args []string) error {
 				object:  bucketAdapter{&sourcev1.Bucket{}},
 			}.run(nil, []string{helmRelease.Spec.Chart.Spec.SourceRef.Name})
 		}
+		rootArgs.namespace = nsCopy
 		if err != nil {
 			return err
 		}
-		rootArgs.namespace = nsCopy
 	}
 
 	lastHandledReconcileAt := helmRelease.Status.LastHandledReconcileAt
@@ -128,9 +130,9 @@ func reconcileHrCmdRun(cmd *cobra.Command, args []string) error {
 	logger.Successf("HelmRelease annotated")
 
 	logger.Waitingf("waiting for HelmRelease reconciliation")
-	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
+	if err := waitForReconciliation(ctx,
 		helmReleaseReconciliationHandled(ctx, kubeClient, namespacedName, &helmRelease, lastHandledReconcileAt),
-	); err != nil {
+		&helmRelease.Status.Conditions); err != nil {
 		return err
 	}
 	logger.Successf("HelmRelease reconciliation completed")
@@ -177,3 +179,7 @@ func requestHelmReleaseReconciliation(ctx context.Context, kubeClient client.Cli
 		return kubeClient.Update(ctx, helmRelease)
 	})
 }
+
+func (obj helmReleaseAdapter) lastHandledReconcileRequest() string {
+	return obj.Status.GetLastHandledReconcileRequest()
+}
@@ -25,9 +25,10 @@ import (
 )
 
 var reconcileImageRepositoryCmd = &cobra.Command{
-	Use:   "repository [name]",
-	Short: "Reconcile an ImageRepository",
-	Long:  `The reconcile image repository command triggers a reconciliation of an ImageRepository resource and waits for it to finish.`,
+	Use:               "repository [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&imagev1.ImageRepositoryList{}),
+	Short:             "Reconcile an ImageRepository",
+	Long:              `The reconcile image repository command triggers a reconciliation of an ImageRepository resource and waits for it to finish.`,
 	Example: `  # Trigger an scan for an existing image repository
   flux reconcile image repository alpine
 `,
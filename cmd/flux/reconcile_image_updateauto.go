@@ -21,16 +21,19 @@ import (
 
 	"github.com/spf13/cobra"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	autov1 "github.com/fluxcd/image-automation-controller/api/v1alpha1"
 	meta "github.com/fluxcd/pkg/apis/meta"
 )
 
 var reconcileImageUpdateCmd = &cobra.Command{
-	Use:   "update [name]",
-	Short: "Reconcile an ImageUpdateAutomation",
-	Long:  `The reconcile image update command triggers a reconciliation of an ImageUpdateAutomation resource and waits for it to finish.`,
-	Example: `  # Trigger an automation run for an existing image update automation
+	Use:               "update [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&autov1.ImageUpdateAutomationList{}),
+	Short:             "Reconcile an ImageUpdateAutomation",
+	Long:              `The reconcile image update command triggers a reconciliation of an ImageUpdateAutomation resource and waits for it to finish.`,
+	Example: `  # Trigger an automation run for an existing image update automation,
+  # and print the commit it pushed
   flux reconcile image update latest-images
 `,
 	RunE: reconcileCommand{
@@ -60,3 +63,11 @@ func (obj imageUpdateAutomationAdapter) successMessage() string {
 	}
 	return "automation not yet run"
 }
+
+func (obj imageUpdateAutomationAdapter) changesMessage(previous client.Object) string {
+	commit := obj.Status.LastPushCommit
+	if prev, ok := previous.(*autov1.ImageUpdateAutomation); ok && commit != "" && commit != prev.Status.LastPushCommit {
+		return "pushed commit " + commit
+	}
+	return "no changes"
+}
@@ -21,12 +21,17 @@ import (
 	"fmt"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/fluxcd/flux2/internal/utils"
 	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/dependency"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -36,39 +41,77 @@ import (
 )
 
 var reconcileKsCmd = &cobra.Command{
-	Use:     "kustomization [name]",
-	Aliases: []string{"ks"},
-	Short:   "Reconcile a Kustomization resource",
+	Use:               "kustomization [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&kustomizev1.KustomizationList{}),
+	Aliases:           []string{"ks"},
+	Short:             "Reconcile a Kustomization resource",
 	Long: `
-The reconcile kustomization command triggers a reconciliation of a Kustomization resource and waits for it to finish.`,
+The reconcile kustomization command triggers a reconciliation of a Kustomization resource and waits for it to finish.
+When the health checks of the Kustomization fail or time out, the status of each health check is reported.
+With --all, the Kustomizations of the namespace are reconciled in the order of their dependencies,
+and the Kustomizations depending on one that failed are skipped.
+The suspended Kustomizations are skipped, or only annotated with --include-suspended to be reconciled once resumed.`,
 	Example: `  # Trigger a Kustomization apply outside of the reconciliation interval
   flux reconcile kustomization podinfo
 
   # Trigger a sync of the Kustomization's source and apply changes
   flux reconcile kustomization podinfo --with-source
+
+  # Wait for the manifests to be re-applied even when the source revision is unchanged
+  flux reconcile kustomization podinfo --force
+
+  # Wait up to 20 minutes for a large Kustomization to be applied
+  flux reconcile kustomization podinfo --timeout=20m
+
+  # Reconcile all the Kustomizations of the cluster, dependencies first
+  flux reconcile kustomization --all --all-namespaces
 `,
 	RunE: reconcileKsCmdRun,
 }
 
 type reconcileKsFlags struct {
 	syncKsWithSource bool
+	force            bool
+	all              bool
+	allNamespaces    bool
+	includeSuspended bool
 }
 
 var rksArgs reconcileKsFlags
 
 func init() {
 	reconcileKsCmd.Flags().BoolVar(&rksArgs.syncKsWithSource, "with-source", false, "reconcile Kustomization source")
+	reconcileKsCmd.Flags().BoolVar(&rksArgs.force, "force", false,
+		"wait for the controller to handle this reconcile request, which re-applies the manifests even if the source revision is unchanged")
+	reconcileKsCmd.Flags().BoolVar(&rksArgs.all, "all", false,
+		"reconcile all the Kustomizations of the namespace in the order of their dependencies")
+	reconcileKsCmd.Flags().BoolVarP(&rksArgs.allNamespaces, "all-namespaces", "A", false,
+		"reconcile the Kustomizations of all namespaces with --all")
+	reconcileKsCmd.Flags().BoolVar(&rksArgs.includeSuspended, "include-suspended", false,
+		"annotate the suspended Kustomizations with --all, for them to be reconciled once resumed")
 
 	reconcileCmd.AddCommand(reconcileKsCmd)
 }
 
 func reconcileKsCmdRun(cmd *cobra.Command, args []string) error {
+	if (rksArgs.allNamespaces || rksArgs.includeSuspended) && !rksArgs.all {
+		return fmt.Errorf("--all-namespaces and --include-suspended can only be used with --all")
+	}
+	if rksArgs.all {
+		if len(args) > 0 {
+			return fmt.Errorf("names can't be given with --all")
+		}
+		if rksArgs.syncKsWithSource {
+			return fmt.Errorf("--with-source can't be used with --all")
+		}
+		return reconcileAllKustomizations()
+	}
 	if len(args) < 1 {
 		return fmt.Errorf("Kustomization name is required")
 	}
 	name := args[0]
 
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
 	defer cancel()
 
 	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
@@ -107,35 +150,253 @@ func reconcileKsCmdRun(cmd *cobra.Command, args []string) error {
 				object:  bucketAdapter{&sourcev1.Bucket{}},
 			}.run(nil, []string{kustomization.Spec.SourceRef.Name})
 		}
+		rootArgs.namespace = nsCopy
 		if err != nil {
 			return err
 		}
-		rootArgs.namespace = nsCopy
 	}
 
+	return reconcileKustomization(kubeClient, namespacedName, &kustomization, rksArgs.force)
+}
+
+// reconcileSourceConsumers triggers the reconciliation of the
+// Kustomizations that reference the given source, dependencies first,
+// and waits for each of them to be ready. Circular dependencies and
+// references to missing Kustomizations are reported before any
+// reconciliation is requested.
+func reconcileSourceConsumers(sourceKind, sourceName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	var list kustomizev1.KustomizationList
+	if err := kubeClient.List(ctx, &list); err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for _, k := range list.Items {
+		existing[types.NamespacedName{Namespace: k.Namespace, Name: k.Name}.String()] = true
+	}
+
+	consumers := make(map[string]kustomizev1.Kustomization)
+	var dependents []dependency.Dependent
+	for _, k := range list.Items {
+		ref := k.Spec.SourceRef
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = k.Namespace
+		}
+		if ref.Kind != sourceKind || ref.Name != sourceName || namespace != rootArgs.namespace {
+			continue
+		}
+		name, deps := k.GetDependsOn()
+		for _, d := range deps {
+			if d.Namespace == "" {
+				d.Namespace = k.Namespace
+			}
+			if !existing[d.String()] {
+				return fmt.Errorf("Kustomization %s depends on %s which does not exist", name, d)
+			}
+		}
+		consumers[name.String()] = k
+		dependents = append(dependents, k)
+	}
+
+	if len(consumers) == 0 {
+		logger.Successf("no Kustomizations reference %s %s", sourceKind, sourceName)
+		return nil
+	}
+
+	sorted, err := dependency.Sort(dependents)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range sorted {
+		kustomization := consumers[ref.String()]
+		if kustomization.Spec.Suspend {
+			logger.Failuref("skipping suspended Kustomization %s", ref)
+			continue
+		}
+		if err := reconcileKustomization(kubeClient, types.NamespacedName(ref), &kustomization, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileAllKustomizations reconciles the Kustomizations of the
+// namespace, or of all namespaces, in the order of their dependencies
+// and prints a summary table. Circular dependencies are reported
+// before any reconciliation is requested.
+func reconcileAllKustomizations() error {
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	var listOpts []client.ListOption
+	if !rksArgs.allNamespaces {
+		listOpts = append(listOpts, client.InNamespace(rootArgs.namespace))
+	}
+	var list kustomizev1.KustomizationList
+	if err := kubeClient.List(ctx, &list, listOpts...); err != nil {
+		return err
+	}
+	if len(list.Items) == 0 {
+		logger.Failuref("no Kustomization objects found")
+		return nil
+	}
+
+	kustomizations := make(map[string]kustomizev1.Kustomization)
+	var dependents []dependency.Dependent
+	for _, k := range list.Items {
+		name, _ := k.GetDependsOn()
+		kustomizations[name.String()] = k
+		dependents = append(dependents, k)
+	}
+	sorted, err := dependency.Sort(dependents)
+	if err != nil {
+		return err
+	}
+
+	// the Kustomizations that failed or were skipped, for their
+	// dependents to be skipped as well
+	notReady := make(map[string]bool)
+	var results []reconcilePlanResult
+	for _, ref := range sorted {
+		kustomization := kustomizations[ref.String()]
+		namespacedName := types.NamespacedName(ref)
+		result := reconcilePlanResult{entry: reconcilePlanEntry{
+			Kind:      kustomizev1.KustomizationKind,
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+		}}
+
+		_, deps := kustomization.GetDependsOn()
+		for _, d := range deps {
+			if d.Namespace == "" {
+				d.Namespace = kustomization.Namespace
+			}
+			if notReady[d.String()] {
+				result.skipped, result.note = true, fmt.Sprintf("dependency %s is not ready", d)
+				break
+			}
+		}
+
+		switch {
+		case result.skipped:
+		case kustomization.Spec.Suspend && rksArgs.includeSuspended:
+			requestCtx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
+			_, err := requestKustomizeReconciliation(requestCtx, kubeClient, namespacedName, &kustomization)
+			cancel()
+			result.err, result.requested, result.note = err, err == nil, "suspended"
+		case kustomization.Spec.Suspend:
+			result.skipped, result.note = true, "suspended"
+		default:
+			start := time.Now()
+			result.err = reconcileKustomization(kubeClient, namespacedName, &kustomization, rksArgs.force)
+			result.duration = time.Since(start)
+			if result.err != nil {
+				logger.Failuref("Kustomization %s: %s", ref, result.err)
+			}
+		}
+		if result.err != nil || result.skipped || result.requested {
+			notReady[ref.String()] = true
+		}
+		results = append(results, result)
+	}
+
+	return printReconcilePlanResults(results)
+}
+
+// reconcileKustomization requests the reconciliation of the
+// Kustomization and waits for it to be ready. When forced, it waits
+// for the controller to handle this exact request, so that the apply
+// is known to have happened after it, even if the revision is unchanged.
+func reconcileKustomization(kubeClient client.Client,
+	namespacedName types.NamespacedName, kustomization *kustomizev1.Kustomization, force bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
+	defer cancel()
+
 	lastHandledReconcileAt := kustomization.Status.LastHandledReconcileAt
-	logger.Actionf("annotating Kustomization %s in %s namespace", name, rootArgs.namespace)
-	if err := requestKustomizeReconciliation(ctx, kubeClient, namespacedName, &kustomization); err != nil {
+	logger.Actionf("annotating Kustomization %s in %s namespace", namespacedName.Name, namespacedName.Namespace)
+	requestedAt, err := requestKustomizeReconciliation(ctx, kubeClient, namespacedName, kustomization)
+	if err != nil {
 		return err
 	}
 	logger.Successf("Kustomization annotated")
 
+	condition := kustomizeReconciliationHandled(ctx, kubeClient, namespacedName, kustomization, lastHandledReconcileAt)
+	if force {
+		condition = kustomizeReconciliationRequestHandled(ctx, kubeClient, namespacedName, kustomization, requestedAt)
+	}
 	logger.Waitingf("waiting for Kustomization reconciliation")
-	if err := wait.PollImmediate(
-		rootArgs.pollInterval, rootArgs.timeout,
-		kustomizeReconciliationHandled(ctx, kubeClient, namespacedName, &kustomization, lastHandledReconcileAt),
-	); err != nil {
+	if err := waitForReconciliation(ctx, condition, &kustomization.Status.Conditions); err != nil {
+		reportHealthChecks(kubeClient, kustomization)
 		return err
 	}
 	logger.Successf("Kustomization reconciliation completed")
 
-	if apimeta.IsStatusConditionFalse(kustomization.Status.Conditions, meta.ReadyCondition) {
+	if c := apimeta.FindStatusCondition(kustomization.Status.Conditions, meta.ReadyCondition); c != nil && c.Status == metav1.ConditionFalse {
+		if c.Reason == kustomizev1.HealthCheckFailedReason {
+			reportHealthChecks(kubeClient, kustomization)
+		}
 		return fmt.Errorf("Kustomization reconciliation failed")
 	}
 	logger.Successf("reconciled revision %s", kustomization.Status.LastAppliedRevision)
 	return nil
 }
 
+// reportHealthChecks logs the status of each health check of the
+// Kustomization, so that the ones that failed can be told apart.
+func reportHealthChecks(kubeClient client.Client, kustomization *kustomizev1.Kustomization) {
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
+	defer cancel()
+
+	for _, check := range kustomization.Spec.HealthChecks {
+		ref := fmt.Sprintf("%s/%s.%s", check.Kind, check.Name, check.Namespace)
+		apiVersion := check.APIVersion
+		if apiVersion == "" {
+			// the controller defaults the workloads to apps/v1
+			apiVersion = "apps/v1"
+		}
+		object := &unstructured.Unstructured{}
+		object.SetAPIVersion(apiVersion)
+		object.SetKind(check.Kind)
+		namespacedName := types.NamespacedName{
+			Namespace: check.Namespace,
+			Name:      check.Name,
+		}
+		if err := kubeClient.Get(ctx, namespacedName, object); err != nil {
+			if apierrors.IsNotFound(err) {
+				logger.Failuref("health check %s failed: not found", ref)
+			} else {
+				logger.Failuref("health check %s failed: %s", ref, err)
+			}
+			continue
+		}
+		result, err := status.Compute(object)
+		if err != nil {
+			logger.Failuref("health check %s failed: %s", ref, err)
+			continue
+		}
+		if result.Status != status.CurrentStatus {
+			logger.Failuref("health check %s failed: %s %s", ref, result.Status, result.Message)
+			continue
+		}
+		logger.Successf("health check %s passed", ref)
+	}
+}
+
 func kustomizeReconciliationHandled(ctx context.Context, kubeClient client.Client,
 	namespacedName types.NamespacedName, kustomization *kustomizev1.Kustomization, lastHandledReconcileAt string) wait.ConditionFunc {
 	return func() (bool, error) {
@@ -147,19 +408,36 @@ func kustomizeReconciliationHandled(ctx context.Context, kubeClient client.Clien
 	}
 }
 
+// kustomizeReconciliationRequestHandled is met once the controller has
+// handled the reconcile request with the given timestamp.
+func kustomizeReconciliationRequestHandled(ctx context.Context, kubeClient client.Client,
+	namespacedName types.NamespacedName, kustomization *kustomizev1.Kustomization, requestedAt string) wait.ConditionFunc {
+	return func() (bool, error) {
+		err := kubeClient.Get(ctx, namespacedName, kustomization)
+		if err != nil {
+			return false, err
+		}
+		return kustomization.Status.LastHandledReconcileAt == requestedAt, nil
+	}
+}
+
+// requestKustomizeReconciliation annotates the Kustomization with a
+// reconcile request, and returns the timestamp of the request. The
+// controller applies the manifests on every request, whether or not
+// the source revision changed.
 func requestKustomizeReconciliation(ctx context.Context, kubeClient client.Client,
-	namespacedName types.NamespacedName, kustomization *kustomizev1.Kustomization) error {
-	return retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+	namespacedName types.NamespacedName, kustomization *kustomizev1.Kustomization) (string, error) {
+	var requestedAt string
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
 		if err := kubeClient.Get(ctx, namespacedName, kustomization); err != nil {
 			return err
 		}
+		requestedAt = time.Now().Format(time.RFC3339Nano)
 		if kustomization.Annotations == nil {
-			kustomization.Annotations = map[string]string{
-				meta.ReconcileRequestAnnotation: time.Now().Format(time.RFC3339Nano),
-			}
-		} else {
-			kustomization.Annotations[meta.ReconcileRequestAnnotation] = time.Now().Format(time.RFC3339Nano)
+			kustomization.Annotations = map[string]string{}
 		}
+		kustomization.Annotations[meta.ReconcileRequestAnnotation] = requestedAt
 		return kubeClient.Update(ctx, kustomization)
 	})
+	return requestedAt, err
 }
@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	autov1 "github.com/fluxcd/image-automation-controller/api/v1alpha1"
+	imagev1 "github.com/fluxcd/image-reflector-controller/api/v1alpha1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+// reconcilePlanEntry is an object to reconcile, as listed in the
+// file given with --from-file.
+type reconcilePlanEntry struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (e reconcilePlanEntry) namespacedName() types.NamespacedName {
+	return types.NamespacedName{Namespace: e.Namespace, Name: e.Name}
+}
+
+// reconcilePlanKinds are the kinds that can be listed in a plan, with
+// the adapter used to reconcile them. The Kustomizations are
+// reconciled separately, for their health checks to be reported.
+var reconcilePlanKinds = map[string]func() reconcilable{
+	sourcev1.GitRepositoryKind:  func() reconcilable { return gitRepositoryAdapter{&sourcev1.GitRepository{}} },
+	sourcev1.HelmRepositoryKind: func() reconcilable { return helmRepositoryAdapter{&sourcev1.HelmRepository{}} },
+	sourcev1.BucketKind:         func() reconcilable { return bucketAdapter{&sourcev1.Bucket{}} },
+	helmv2.HelmReleaseKind:      func() reconcilable { return helmReleaseAdapter{&helmv2.HelmRelease{}} },
+	imagev1.ImageRepositoryKind: func() reconcilable { return imageRepositoryAdapter{&imagev1.ImageRepository{}} },
+	autov1.ImageUpdateAutomationKind: func() reconcilable {
+		return imageUpdateAutomationAdapter{&autov1.ImageUpdateAutomation{}}
+	},
+}
+
+// reconcilePlanResult is the outcome of the reconciliation of an
+// entry, for the summary table. An entry is requested when its
+// reconciliation was requested without waiting for it.
+type reconcilePlanResult struct {
+	entry     reconcilePlanEntry
+	err       error
+	skipped   bool
+	requested bool
+	note      string
+	duration  time.Duration
+}
+
+func reconcilePlanCmdRun(cmd *cobra.Command, args []string) error {
+	if reconcileArgs.fromFile == "" {
+		if reconcileArgs.parallel {
+			return fmt.Errorf("--parallel can only be used with --from-file")
+		}
+		return cmd.Help()
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("names can't be given with --from-file")
+	}
+
+	entries, err := readReconcilePlan(reconcileArgs.fromFile)
+	if err != nil {
+		return err
+	}
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	results := make([]reconcilePlanResult, len(entries))
+	if reconcileArgs.parallel {
+		var wg sync.WaitGroup
+		for i, entry := range entries {
+			wg.Add(1)
+			go func(i int, entry reconcilePlanEntry) {
+				defer wg.Done()
+				results[i] = reconcilePlanEntryRun(kubeClient, entry)
+			}(i, entry)
+		}
+		wg.Wait()
+	} else {
+		failed := false
+		for i, entry := range entries {
+			if failed {
+				results[i] = reconcilePlanResult{entry: entry, skipped: true}
+				continue
+			}
+			results[i] = reconcilePlanEntryRun(kubeClient, entry)
+			failed = results[i].err != nil
+		}
+	}
+
+	return printReconcilePlanResults(results)
+}
+
+// readReconcilePlan reads the YAML or JSON list of entries from the
+// file, and checks them all before any reconciliation is requested.
+func readReconcilePlan(filename string) ([]reconcilePlanEntry, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading plan failed: %w", err)
+	}
+	var entries []reconcilePlanEntry
+	if err := yaml.UnmarshalStrict(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing plan '%s' failed: %w", filename, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("plan '%s' has no entries", filename)
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if _, ok := reconcilePlanKinds[entry.Kind]; !ok && entry.Kind != kustomizev1.KustomizationKind {
+			return nil, fmt.Errorf("entry %d of plan '%s': unsupported kind '%s'", i+1, filename, entry.Kind)
+		}
+		if entry.Name == "" {
+			return nil, fmt.Errorf("entry %d of plan '%s': name is required", i+1, filename)
+		}
+		if entry.Namespace == "" {
+			entry.Namespace = rootArgs.namespace
+		}
+	}
+	return entries, nil
+}
+
+// reconcilePlanEntryRun reconciles the object of the entry and waits
+// for it to be ready, within the reconcile timeout.
+func reconcilePlanEntryRun(kubeClient client.Client, entry reconcilePlanEntry) reconcilePlanResult {
+	start := time.Now()
+	var err error
+	if entry.Kind == kustomizev1.KustomizationKind {
+		err = reconcilePlanKustomization(kubeClient, entry.namespacedName())
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
+		defer cancel()
+		err = reconcileObject(ctx, kubeClient, entry.Kind, entry.namespacedName(), reconcilePlanKinds[entry.Kind]())
+	}
+	if err != nil {
+		logger.Failuref("%s %s: %s", entry.Kind, entry.namespacedName(), err)
+	}
+	return reconcilePlanResult{entry: entry, err: err, duration: time.Since(start)}
+}
+
+func reconcilePlanKustomization(kubeClient client.Client, namespacedName types.NamespacedName) error {
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
+	defer cancel()
+
+	var kustomization kustomizev1.Kustomization
+	if err := kubeClient.Get(ctx, namespacedName, &kustomization); err != nil {
+		return err
+	}
+	if kustomization.Spec.Suspend {
+		return fmt.Errorf("resource is suspended")
+	}
+	return reconcileKustomization(kubeClient, namespacedName, &kustomization, false)
+}
+
+// printReconcilePlanResults prints the summary table of the plan, and
+// returns an error when any of the entries failed.
+func printReconcilePlanResults(results []reconcilePlanResult) error {
+	header := []string{"Kind", "Namespace", "Name", "Result", "Duration", "Message"}
+	var rows [][]string
+	var failed int
+	for _, r := range results {
+		result, duration, message := "reconciled", r.duration.Round(time.Second).String(), r.note
+		switch {
+		case r.skipped:
+			result, duration = "skipped", "-"
+		case r.requested:
+			result, duration = "requested", "-"
+		case r.err != nil:
+			result, message = "failed", r.err.Error()
+			failed++
+		}
+		rows = append(rows, []string{r.entry.Kind, r.entry.Namespace, r.entry.Name, result, duration, message})
+	}
+
+	fmt.Println()
+	utils.PrintTable(os.Stdout, header, rows)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d objects failed to reconcile", failed, len(results))
+	}
+	return nil
+}
@@ -17,15 +17,89 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fluxcd/flux2/internal/utils"
 )
 
 var reconcileSourceCmd = &cobra.Command{
-	Use:   "source",
+	Use:   "source [name]",
 	Short: "Reconcile sources",
-	Long:  "The reconcile source sub-commands trigger a reconciliation of sources.",
+	Long: `The reconcile source sub-commands trigger a reconciliation of sources.
+When given only a name, the source of that name is looked up across the source kinds
+in the namespace, and reconciled if exactly one source matches.`,
+	Example: `  # Trigger a reconciliation for an existing source of any kind
+  flux reconcile source podinfo
+`,
+	RunE: reconcileSourceCmdRun,
+}
+
+// reconcileSourceKinds are the source kinds a source is looked up in
+// when no kind is given, with the name of their sub-command.
+var reconcileSourceKinds = []struct {
+	kind    string
+	command string
+}{
+	{sourcev1.GitRepositoryKind, "git"},
+	{sourcev1.HelmRepositoryKind, "helm"},
+	{sourcev1.BucketKind, "bucket"},
 }
 
 func init() {
 	reconcileCmd.AddCommand(reconcileSourceCmd)
 }
+
+func reconcileSourceCmdRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return cmd.Help()
+	}
+	name := args[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	namespacedName := types.NamespacedName{
+		Namespace: rootArgs.namespace,
+		Name:      name,
+	}
+
+	var matches []string
+	var commands []string
+	var object reconcilable
+	for _, source := range reconcileSourceKinds {
+		candidate := reconcilePlanKinds[source.kind]()
+		err := kubeClient.Get(ctx, namespacedName, candidate.asClientObject())
+		switch {
+		case err == nil:
+			matches = append(matches, source.kind)
+			commands = append(commands, source.command)
+			object = candidate
+		case apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err):
+		default:
+			return err
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("no source named '%s' found in %s namespace", name, rootArgs.namespace)
+	case 1:
+		return reconcileObject(ctx, kubeClient, matches[0], namespacedName, object)
+	default:
+		return fmt.Errorf("the name '%s' is ambiguous, it matches the sources %s in %s namespace, specify the kind with 'flux reconcile source <%s> %s'",
+			name, strings.Join(matches, ", "), rootArgs.namespace, strings.Join(commands, "|"), name)
+	}
+}
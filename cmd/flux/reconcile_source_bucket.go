@@ -31,9 +31,10 @@ import (
 )
 
 var reconcileSourceBucketCmd = &cobra.Command{
-	Use:   "bucket [name]",
-	Short: "Reconcile a Bucket source",
-	Long:  `The reconcile source command triggers a reconciliation of a Bucket resource and waits for it to finish.`,
+	Use:               "bucket [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.BucketList{}),
+	Short:             "Reconcile a Bucket source",
+	Long:              `The reconcile source command triggers a reconciliation of a Bucket resource and waits for it to finish.`,
 	Example: `  # Trigger a reconciliation for an existing source
   flux reconcile source bucket podinfo
 `,
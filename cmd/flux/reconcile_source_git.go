@@ -18,27 +18,49 @@ package main
 
 import (
 	"fmt"
+
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/spf13/cobra"
 )
 
 var reconcileSourceGitCmd = &cobra.Command{
-	Use:   "git [name]",
-	Short: "Reconcile a GitRepository source",
-	Long:  `The reconcile source command triggers a reconciliation of a GitRepository resource and waits for it to finish.`,
+	Use:               "git [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.GitRepositoryList{}),
+	Short:             "Reconcile a GitRepository source",
+	Long:              `The reconcile source command triggers a reconciliation of a GitRepository resource and waits for it to finish.`,
 	Example: `  # Trigger a git pull for an existing source
   flux reconcile source git podinfo
+
+  # Trigger a git pull and reconcile the Kustomizations using the source
+  flux reconcile source git podinfo --with-source-consumers
 `,
-	RunE: reconcileCommand{
-		apiType: gitRepositoryType,
-		object:  gitRepositoryAdapter{&sourcev1.GitRepository{}},
-	}.run,
+	RunE: reconcileSourceGitCmdRun,
+}
+
+type reconcileSourceGitFlags struct {
+	withSourceConsumers bool
 }
 
+var rsgArgs reconcileSourceGitFlags
+
 func init() {
+	reconcileSourceGitCmd.Flags().BoolVar(&rsgArgs.withSourceConsumers, "with-source-consumers", false,
+		"reconcile the Kustomizations that reference the GitRepository, in dependency order")
+
 	reconcileSourceCmd.AddCommand(reconcileSourceGitCmd)
 }
 
+func reconcileSourceGitCmdRun(cmd *cobra.Command, args []string) error {
+	err := reconcileCommand{
+		apiType: gitRepositoryType,
+		object:  gitRepositoryAdapter{&sourcev1.GitRepository{}},
+	}.run(cmd, args)
+	if err != nil || !rsgArgs.withSourceConsumers {
+		return err
+	}
+	return reconcileSourceConsumers(sourcev1.GitRepositoryKind, args[0])
+}
+
 func (obj gitRepositoryAdapter) lastHandledReconcileRequest() string {
 	return obj.Status.GetLastHandledReconcileRequest()
 }
@@ -23,9 +23,10 @@ import (
 )
 
 var reconcileSourceHelmCmd = &cobra.Command{
-	Use:   "helm [name]",
-	Short: "Reconcile a HelmRepository source",
-	Long:  `The reconcile source command triggers a reconciliation of a HelmRepository resource and waits for it to finish.`,
+	Use:               "helm [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.HelmRepositoryList{}),
+	Short:             "Reconcile a HelmRepository source",
+	Long:              `The reconcile source command triggers a reconciliation of a HelmRepository resource and waits for it to finish.`,
 	Example: `  # Trigger a reconciliation for an existing source
   flux reconcile source helm podinfo
 `,
@@ -18,11 +18,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/fluxcd/flux2/internal/utils"
 )
@@ -33,7 +37,18 @@ var resumeCmd = &cobra.Command{
 	Long:  "The resume sub-commands resume a suspended resource.",
 }
 
+type resumeFlags struct {
+	all  bool
+	wait bool
+}
+
+var resumeArgs resumeFlags
+
 func init() {
+	resumeCmd.PersistentFlags().BoolVar(&resumeArgs.all, "all", false,
+		"resume all the resources of that kind in the namespace")
+	resumeCmd.PersistentFlags().BoolVar(&resumeArgs.wait, "wait", true,
+		"wait for the resumed resources to be ready, or to fail, within the timeout")
 	rootCmd.AddCommand(resumeCmd)
 }
 
@@ -47,45 +62,104 @@ type resumable interface {
 type resumeCommand struct {
 	apiType
 	object resumable
+	list   listAdapter
 }
 
 func (resume resumeCommand) run(cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
+	if len(args) < 1 && !resumeArgs.all {
 		return fmt.Errorf("%s name is required", resume.humanKind)
 	}
-	name := args[0]
-
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
 
 	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
 	if err != nil {
 		return err
 	}
 
-	namespacedName := types.NamespacedName{
-		Namespace: rootArgs.namespace,
-		Name:      name,
-	}
-
-	err = kubeClient.Get(ctx, namespacedName, resume.object.asClientObject())
+	names, err := resumeNames(kubeClient, resume.list.asClientList(), args)
 	if err != nil {
 		return err
 	}
+	if len(names) == 0 {
+		logger.Failuref("no %s objects found in %s namespace", resume.kind, rootArgs.namespace)
+		return nil
+	}
 
-	logger.Actionf("resuming %s %s in %s namespace", resume.humanKind, name, rootArgs.namespace)
-	resume.object.setUnsuspended()
-	if err := kubeClient.Update(ctx, resume.object.asClientObject()); err != nil {
-		return err
+	var summary resumeWaitSummary
+	defer summary.print(len(names))
+	return forEachName(names, "resumed", resume.humanKind, func(name string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+		defer cancel()
+
+		namespacedName := types.NamespacedName{
+			Namespace: rootArgs.namespace,
+			Name:      name,
+		}
+
+		err := kubeClient.Get(ctx, namespacedName, resume.object.asClientObject())
+		if err != nil {
+			return err
+		}
+
+		logger.Actionf("resuming %s %s in %s namespace", resume.humanKind, name, rootArgs.namespace)
+		patch := client.MergeFrom(resume.object.asClientObject().DeepCopyObject().(client.Object))
+		resume.object.setUnsuspended()
+		clearSuspendAnnotations(resume.object.asClientObject())
+		if err := kubeClient.Patch(ctx, resume.object.asClientObject(), patch); err != nil {
+			return err
+		}
+		logger.Successf("%s resumed", resume.humanKind)
+
+		if !resumeArgs.wait {
+			return nil
+		}
+		if err := summary.wait(ctx, name, resume.kind,
+			isReady(ctx, kubeClient, namespacedName, resume.object), resume.object.GetStatusConditions()); err != nil {
+			return err
+		}
+		logger.Successf(resume.object.successMessage())
+		return nil
+	})
+}
+
+// resumeWaitSummary records which of the resumed objects became ready
+// and which timed out, to report them when several objects are resumed.
+type resumeWaitSummary struct {
+	ready    []string
+	timedOut []string
+}
+
+// wait waits for the resumed object to be ready, with the same backoff
+// as the reconcile commands.
+func (s *resumeWaitSummary) wait(ctx context.Context, name, kind string,
+	condition wait.ConditionFunc, conditions *[]metav1.Condition) error {
+	logger.Waitingf("waiting for %s reconciliation", kind)
+	err := waitWithBackoff(ctx, condition, conditions, rootArgs.pollInterval, rootArgs.timeout)
+	switch {
+	case err == nil:
+		s.ready = append(s.ready, name)
+		logger.Successf("%s reconciliation completed", kind)
+	case errors.Is(err, errReconcileTimeout):
+		s.timedOut = append(s.timedOut, name)
 	}
-	logger.Successf("%s resumed", resume.humanKind)
+	return err
+}
 
-	logger.Waitingf("waiting for %s reconciliation", resume.kind)
-	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
-		isReady(ctx, kubeClient, namespacedName, resume.object)); err != nil {
-		return err
+func (s resumeWaitSummary) print(total int) {
+	if total < 2 || !resumeArgs.wait {
+		return
+	}
+	if len(s.ready) > 0 {
+		logger.Successf("ready: %s", strings.Join(s.ready, ", "))
 	}
-	logger.Successf("%s reconciliation completed", resume.kind)
-	logger.Successf(resume.object.successMessage())
-	return nil
+	if len(s.timedOut) > 0 {
+		logger.Failuref("timed out: %s", strings.Join(s.timedOut, ", "))
+	}
+}
+
+// resumeNames returns the names of the objects to resume, from the
+// arguments or the list of the objects in the namespace.
+func resumeNames(kubeClient client.Client, list client.ObjectList, args []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+	return objectNames(ctx, kubeClient, list, args, resumeArgs.all)
 }
@@ -34,8 +34,9 @@ import (
 )
 
 var resumeAlertCmd = &cobra.Command{
-	Use:   "alert [name]",
-	Short: "Resume a suspended Alert",
+	Use:               "alert [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.AlertList{}),
+	Short:             "Resume a suspended Alert",
 	Long: `The resume command marks a previously suspended Alert resource for reconciliation and waits for it to
 finish the apply.`,
 	Example: `  # Resume reconciliation for an existing Alert
@@ -49,43 +50,55 @@ func init() {
 }
 
 func resumeAlertCmdRun(cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
+	if len(args) < 1 && !resumeArgs.all {
 		return fmt.Errorf("Alert name is required")
 	}
-	name := args[0]
-
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
 
 	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
 	if err != nil {
 		return err
 	}
 
-	namespacedName := types.NamespacedName{
-		Namespace: rootArgs.namespace,
-		Name:      name,
-	}
-	var alert notificationv1.Alert
-	err = kubeClient.Get(ctx, namespacedName, &alert)
+	names, err := resumeNames(kubeClient, &notificationv1.AlertList{}, args)
 	if err != nil {
 		return err
 	}
-
-	logger.Actionf("resuming Alert %s in %s namespace", name, rootArgs.namespace)
-	alert.Spec.Suspend = false
-	if err := kubeClient.Update(ctx, &alert); err != nil {
-		return err
+	if len(names) == 0 {
+		logger.Failuref("no Alert objects found in %s namespace", rootArgs.namespace)
+		return nil
 	}
-	logger.Successf("Alert resumed")
 
-	logger.Waitingf("waiting for Alert reconciliation")
-	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
-		isAlertResumed(ctx, kubeClient, namespacedName, &alert)); err != nil {
-		return err
-	}
-	logger.Successf("Alert reconciliation completed")
-	return nil
+	var summary resumeWaitSummary
+	defer summary.print(len(names))
+	return forEachName(names, "resumed", alertType.humanKind, func(name string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+		defer cancel()
+
+		namespacedName := types.NamespacedName{
+			Namespace: rootArgs.namespace,
+			Name:      name,
+		}
+		var alert notificationv1.Alert
+		err := kubeClient.Get(ctx, namespacedName, &alert)
+		if err != nil {
+			return err
+		}
+
+		logger.Actionf("resuming Alert %s in %s namespace", name, rootArgs.namespace)
+		patch := client.MergeFrom(alert.DeepCopy())
+		alert.Spec.Suspend = false
+		clearSuspendAnnotations(&alert)
+		if err := kubeClient.Patch(ctx, &alert, patch); err != nil {
+			return err
+		}
+		logger.Successf("Alert resumed")
+
+		if !resumeArgs.wait {
+			return nil
+		}
+		return summary.wait(ctx, name, "Alert",
+			isAlertResumed(ctx, kubeClient, namespacedName, &alert), &alert.Status.Conditions)
+	})
 }
 
 func isAlertResumed(ctx context.Context, kubeClient client.Client,
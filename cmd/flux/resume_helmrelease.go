@@ -23,9 +23,10 @@ import (
 )
 
 var resumeHrCmd = &cobra.Command{
-	Use:     "helmrelease [name]",
-	Aliases: []string{"hr"},
-	Short:   "Resume a suspended HelmRelease",
+	Use:               "helmrelease [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&helmv2.HelmReleaseList{}),
+	Aliases:           []string{"hr"},
+	Short:             "Resume a suspended HelmRelease",
 	Long: `The resume command marks a previously suspended HelmRelease resource for reconciliation and waits for it to
 finish the apply.`,
 	Example: `  # Resume reconciliation for an existing Helm release
@@ -34,6 +35,7 @@ finish the apply.`,
 	RunE: resumeCommand{
 		apiType: helmReleaseType,
 		object:  helmReleaseAdapter{&helmv2.HelmRelease{}},
+		list:    helmReleaseListAdapter{&helmv2.HelmReleaseList{}},
 	}.run,
 }
 
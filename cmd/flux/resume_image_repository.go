@@ -23,15 +23,17 @@ import (
 )
 
 var resumeImageRepositoryCmd = &cobra.Command{
-	Use:   "repository [name]",
-	Short: "Resume a suspended ImageRepository",
-	Long:  `The resume command marks a previously suspended ImageRepository resource for reconciliation and waits for it to finish.`,
+	Use:               "repository [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&imagev1.ImageRepositoryList{}),
+	Short:             "Resume a suspended ImageRepository",
+	Long:              `The resume command marks a previously suspended ImageRepository resource for reconciliation and waits for it to finish.`,
 	Example: `  # Resume reconciliation for an existing ImageRepository
   flux resume image repository alpine
 `,
 	RunE: resumeCommand{
 		apiType: imageRepositoryType,
 		object:  imageRepositoryAdapter{&imagev1.ImageRepository{}},
+		list:    imageRepositoryListAdapter{&imagev1.ImageRepositoryList{}},
 	}.run,
 }
 
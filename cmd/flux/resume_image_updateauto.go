@@ -23,15 +23,17 @@ import (
 )
 
 var resumeImageUpdateCmd = &cobra.Command{
-	Use:   "update [name]",
-	Short: "Resume a suspended ImageUpdateAutomation",
-	Long:  `The resume command marks a previously suspended ImageUpdateAutomation resource for reconciliation and waits for it to finish.`,
+	Use:               "update [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&autov1.ImageUpdateAutomationList{}),
+	Short:             "Resume a suspended ImageUpdateAutomation",
+	Long:              `The resume command marks a previously suspended ImageUpdateAutomation resource for reconciliation and waits for it to finish.`,
 	Example: `  # Resume reconciliation for an existing ImageUpdateAutomation
   flux resume image update latest-images
 `,
 	RunE: resumeCommand{
 		apiType: imageUpdateAutomationType,
 		object:  imageUpdateAutomationAdapter{&autov1.ImageUpdateAutomation{}},
+		list:    imageUpdateAutomationListAdapter{&autov1.ImageUpdateAutomationList{}},
 	}.run,
 }
 
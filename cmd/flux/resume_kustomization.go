@@ -24,17 +24,25 @@ import (
 )
 
 var resumeKsCmd = &cobra.Command{
-	Use:     "kustomization [name]",
-	Aliases: []string{"ks"},
-	Short:   "Resume a suspended Kustomization",
+	Use:               "kustomization [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&kustomizev1.KustomizationList{}),
+	Aliases:           []string{"ks"},
+	Short:             "Resume a suspended Kustomization",
 	Long: `The resume command marks a previously suspended Kustomization resource for reconciliation and waits for it to
 finish the apply.`,
 	Example: `  # Resume reconciliation for an existing Kustomization
   flux resume ks podinfo
+
+  # Resume reconciliation for all Kustomizations in a namespace
+  flux resume ks --all --namespace=apps
+
+  # Resume all Kustomizations in a namespace without waiting for them to be ready
+  flux resume ks --all --namespace=apps --wait=false
 `,
 	RunE: resumeCommand{
 		apiType: kustomizationType,
 		object:  kustomizationAdapter{&kustomizev1.Kustomization{}},
+		list:    kustomizationListAdapter{&kustomizev1.KustomizationList{}},
 	}.run,
 }
 
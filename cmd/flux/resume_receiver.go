@@ -34,8 +34,9 @@ import (
 )
 
 var resumeReceiverCmd = &cobra.Command{
-	Use:   "receiver [name]",
-	Short: "Resume a suspended Receiver",
+	Use:               "receiver [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.ReceiverList{}),
+	Short:             "Resume a suspended Receiver",
 	Long: `The resume command marks a previously suspended Receiver resource for reconciliation and waits for it to
 finish the apply.`,
 	Example: `  # Resume reconciliation for an existing Receiver
@@ -49,44 +50,55 @@ func init() {
 }
 
 func resumeReceiverCmdRun(cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
+	if len(args) < 1 && !resumeArgs.all {
 		return fmt.Errorf("Receiver name is required")
 	}
-	name := args[0]
-
-	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
-	defer cancel()
 
 	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
 	if err != nil {
 		return err
 	}
 
-	namespacedName := types.NamespacedName{
-		Namespace: rootArgs.namespace,
-		Name:      name,
-	}
-	var receiver notificationv1.Receiver
-	err = kubeClient.Get(ctx, namespacedName, &receiver)
+	names, err := resumeNames(kubeClient, &notificationv1.ReceiverList{}, args)
 	if err != nil {
 		return err
 	}
-
-	logger.Actionf("resuming Receiver %s in %s namespace", name, rootArgs.namespace)
-	receiver.Spec.Suspend = false
-	if err := kubeClient.Update(ctx, &receiver); err != nil {
-		return err
+	if len(names) == 0 {
+		logger.Failuref("no Receiver objects found in %s namespace", rootArgs.namespace)
+		return nil
 	}
-	logger.Successf("Receiver resumed")
 
-	logger.Waitingf("waiting for Receiver reconciliation")
-	if err := wait.PollImmediate(rootArgs.pollInterval, rootArgs.timeout,
-		isReceiverResumed(ctx, kubeClient, namespacedName, &receiver)); err != nil {
-		return err
-	}
+	var summary resumeWaitSummary
+	defer summary.print(len(names))
+	return forEachName(names, "resumed", receiverType.humanKind, func(name string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+		defer cancel()
 
-	logger.Successf("Receiver reconciliation completed")
-	return nil
+		namespacedName := types.NamespacedName{
+			Namespace: rootArgs.namespace,
+			Name:      name,
+		}
+		var receiver notificationv1.Receiver
+		err := kubeClient.Get(ctx, namespacedName, &receiver)
+		if err != nil {
+			return err
+		}
+
+		logger.Actionf("resuming Receiver %s in %s namespace", name, rootArgs.namespace)
+		patch := client.MergeFrom(receiver.DeepCopy())
+		receiver.Spec.Suspend = false
+		clearSuspendAnnotations(&receiver)
+		if err := kubeClient.Patch(ctx, &receiver, patch); err != nil {
+			return err
+		}
+		logger.Successf("Receiver resumed")
+
+		if !resumeArgs.wait {
+			return nil
+		}
+		return summary.wait(ctx, name, "Receiver",
+			isReceiverResumed(ctx, kubeClient, namespacedName, &receiver), &receiver.Status.Conditions)
+	})
 }
 
 func isReceiverResumed(ctx context.Context, kubeClient client.Client,
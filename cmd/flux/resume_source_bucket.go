@@ -22,15 +22,17 @@ import (
 )
 
 var resumeSourceBucketCmd = &cobra.Command{
-	Use:   "bucket [name]",
-	Short: "Resume a suspended Bucket",
-	Long:  `The resume command marks a previously suspended Bucket resource for reconciliation and waits for it to finish.`,
+	Use:               "bucket [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.BucketList{}),
+	Short:             "Resume a suspended Bucket",
+	Long:              `The resume command marks a previously suspended Bucket resource for reconciliation and waits for it to finish.`,
 	Example: `  # Resume reconciliation for an existing Bucket
   flux resume source bucket podinfo
 `,
 	RunE: resumeCommand{
 		apiType: bucketType,
 		object:  &bucketAdapter{&sourcev1.Bucket{}},
+		list:    bucketListAdapter{&sourcev1.BucketList{}},
 	}.run,
 }
 
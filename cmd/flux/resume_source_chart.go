@@ -24,15 +24,17 @@ import (
 )
 
 var resumeSourceHelmChartCmd = &cobra.Command{
-	Use:   "chart [name]",
-	Short: "Resume a suspended HelmChart",
-	Long:  `The resume command marks a previously suspended HelmChart resource for reconciliation and waits for it to finish.`,
+	Use:               "chart [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.HelmChartList{}),
+	Short:             "Resume a suspended HelmChart",
+	Long:              `The resume command marks a previously suspended HelmChart resource for reconciliation and waits for it to finish.`,
 	Example: `  # Resume reconciliation for an existing HelmChart
   flux resume source chart podinfo
 `,
 	RunE: resumeCommand{
 		apiType: helmChartType,
 		object:  &helmChartAdapter{&sourcev1.HelmChart{}},
+		list:    helmChartListAdapter{&sourcev1.HelmChartList{}},
 	}.run,
 }
 
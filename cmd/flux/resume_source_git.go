@@ -22,15 +22,17 @@ import (
 )
 
 var resumeSourceGitCmd = &cobra.Command{
-	Use:   "git [name]",
-	Short: "Resume a suspended GitRepository",
-	Long:  `The resume command marks a previously suspended GitRepository resource for reconciliation and waits for it to finish.`,
+	Use:               "git [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.GitRepositoryList{}),
+	Short:             "Resume a suspended GitRepository",
+	Long:              `The resume command marks a previously suspended GitRepository resource for reconciliation and waits for it to finish.`,
 	Example: `  # Resume reconciliation for an existing GitRepository
   flux resume source git podinfo
 `,
 	RunE: resumeCommand{
 		apiType: gitRepositoryType,
 		object:  gitRepositoryAdapter{&sourcev1.GitRepository{}},
+		list:    gitRepositoryListAdapter{&sourcev1.GitRepositoryList{}},
 	}.run,
 }
 
@@ -22,15 +22,17 @@ import (
 )
 
 var resumeSourceHelmCmd = &cobra.Command{
-	Use:   "helm [name]",
-	Short: "Resume a suspended HelmRepository",
-	Long:  `The resume command marks a previously suspended HelmRepository resource for reconciliation and waits for it to finish.`,
+	Use:               "helm [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.HelmRepositoryList{}),
+	Short:             "Resume a suspended HelmRepository",
+	Long:              `The resume command marks a previously suspended HelmRepository resource for reconciliation and waits for it to finish.`,
 	Example: `  # Resume reconciliation for an existing HelmRepository
   flux resume source helm bitnami
 `,
 	RunE: resumeCommand{
 		apiType: helmRepositoryType,
 		object:  helmRepositoryAdapter{&sourcev1.HelmRepository{}},
+		list:    helmRepositoryListAdapter{&sourcev1.HelmRepositoryList{}},
 	}.run,
 }
 
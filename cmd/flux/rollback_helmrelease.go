@@ -0,0 +1,275 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/flux2/internal/utils"
+	"github.com/fluxcd/pkg/apis/meta"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+)
+
+var rollbackHrCmd = &cobra.Command{
+	Use:               "helmrelease [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&helmv2.HelmReleaseList{}),
+	Aliases:           []string{"hr"},
+	Short:             "Roll back a HelmRelease to a previous Helm release revision",
+	Long: `
+The rollback helmrelease command reverts a HelmRelease to a revision of its Helm release history.
+Without --to-revision, the revisions found in the Helm storage are listed.
+With --to-revision, the chart version of that revision is pinned on the HelmRelease,
+which is then reconciled into a new Helm release revision.
+Only charts from a HelmRepository can be rolled back, and the values and values references of the HelmRelease
+are kept as they are, roll back the values in the source of the HelmRelease instead.
+Changes made by the rollback are reverted by the next apply of the Kustomization managing the HelmRelease,
+suspend it first to keep the rollback in place.`,
+	Example: `  # List the revisions of a HelmRelease
+  flux rollback hr podinfo
+
+  # Roll back a HelmRelease to the revision 3 of its Helm release
+  flux rollback hr podinfo --to-revision=3
+`,
+	RunE: rollbackHrCmdRun,
+}
+
+type rollbackHelmReleaseFlags struct {
+	toRevision int
+}
+
+var rollbackHrArgs rollbackHelmReleaseFlags
+
+func init() {
+	rollbackHrCmd.Flags().IntVar(&rollbackHrArgs.toRevision, "to-revision", 0,
+		"the Helm release revision to roll back to, the available revisions are listed when not set")
+
+	rollbackCmd.AddCommand(rollbackHrCmd)
+}
+
+// helmReleaseRevision is a revision of a Helm release, as recorded in the
+// Helm storage secrets.
+type helmReleaseRevision struct {
+	Version int `json:"version"`
+	Info    struct {
+		Status       string    `json:"status"`
+		LastDeployed time.Time `json:"last_deployed"`
+		Description  string    `json:"description"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+func rollbackHrCmdRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("HelmRelease name is required")
+	}
+	name := args[0]
+
+	if rollbackHrArgs.toRevision < 0 {
+		return fmt.Errorf("--to-revision must be a positive number")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	namespacedName := types.NamespacedName{
+		Namespace: rootArgs.namespace,
+		Name:      name,
+	}
+
+	var helmRelease helmv2.HelmRelease
+	err = kubeClient.Get(ctx, namespacedName, &helmRelease)
+	if err != nil {
+		return err
+	}
+
+	history, err := helmReleaseHistory(ctx, kubeClient, helmRelease)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("no revisions found for Helm release %s in %s namespace",
+			helmRelease.GetReleaseName(), helmRelease.GetStorageNamespace())
+	}
+
+	if rollbackHrArgs.toRevision == 0 {
+		var rows [][]string
+		for _, rev := range history {
+			rows = append(rows, []string{
+				strconv.Itoa(rev.Version),
+				rev.Info.LastDeployed.Format(time.RFC3339),
+				rev.Info.Status,
+				fmt.Sprintf("%s-%s", rev.Chart.Metadata.Name, rev.Chart.Metadata.Version),
+				rev.Chart.Metadata.AppVersion,
+				rev.Info.Description,
+			})
+		}
+		utils.PrintTable(os.Stdout, []string{"Revision", "Updated", "Status", "Chart", "App version", "Description"}, rows)
+		return nil
+	}
+
+	var target *helmReleaseRevision
+	for i := range history {
+		if history[i].Version == rollbackHrArgs.toRevision {
+			target = &history[i]
+		}
+	}
+	if target == nil {
+		var available []string
+		for _, rev := range history {
+			available = append(available, strconv.Itoa(rev.Version))
+		}
+		return fmt.Errorf("revision %d not found for Helm release %s, available revisions: %v",
+			rollbackHrArgs.toRevision, helmRelease.GetReleaseName(), available)
+	}
+	if target.Version == helmRelease.Status.LastReleaseRevision {
+		return fmt.Errorf("Helm release %s is already at revision %d", helmRelease.GetReleaseName(), target.Version)
+	}
+
+	if helmRelease.Spec.Suspend {
+		return fmt.Errorf("resource is suspended")
+	}
+	if ks, ok := helmRelease.GetLabels()[kustomizeNameLabel]; ok {
+		logger.Warningf("HelmRelease is managed by Kustomization %s/%s, suspend it to keep the rollback from being reverted",
+			helmRelease.GetLabels()[kustomizeNamespaceLabel], ks)
+	}
+	if kind := helmRelease.Spec.Chart.Spec.SourceRef.Kind; kind != sourcev1.HelmRepositoryKind {
+		return fmt.Errorf("the chart version can't be pinned for a chart from a %s, only charts from a %s can be rolled back",
+			kind, sourcev1.HelmRepositoryKind)
+	}
+	if target.Chart.Metadata.Version == helmRelease.Spec.Chart.Spec.Version {
+		return fmt.Errorf("revision %d has the chart version %s of the HelmRelease, roll back the values in the source of the HelmRelease instead",
+			target.Version, target.Chart.Metadata.Version)
+	}
+	logger.Warningf("only the chart version is rolled back, the values of the HelmRelease are kept")
+
+	lastHandledReconcileAt := helmRelease.Status.LastHandledReconcileAt
+	logger.Actionf("rolling back HelmRelease %s in %s namespace to revision %d", name, rootArgs.namespace, target.Version)
+	if err := rollbackHelmRelease(ctx, kubeClient, namespacedName, &helmRelease, *target); err != nil {
+		return err
+	}
+	logger.Successf("HelmRelease updated")
+
+	logger.Waitingf("waiting for HelmRelease reconciliation")
+	if err := waitForReconciliation(ctx,
+		helmReleaseReconciliationHandled(ctx, kubeClient, namespacedName, &helmRelease, lastHandledReconcileAt),
+		&helmRelease.Status.Conditions); err != nil {
+		return err
+	}
+
+	if apimeta.IsStatusConditionFalse(helmRelease.Status.Conditions, meta.ReadyCondition) {
+		_, msg := statusAndMessage(helmRelease.Status.Conditions)
+		return fmt.Errorf("HelmRelease rollback failed: %s", msg)
+	}
+	logger.Successf("rolled back to revision %d, released as revision %d with chart %s",
+		target.Version, helmRelease.Status.LastReleaseRevision, helmRelease.Status.LastAppliedRevision)
+	return nil
+}
+
+// helmReleaseHistory returns the revisions of the Helm release of the
+// HelmRelease ordered by version, from the secrets of the Helm storage.
+func helmReleaseHistory(ctx context.Context, kubeClient client.Client, helmRelease helmv2.HelmRelease) ([]helmReleaseRevision, error) {
+	var secrets corev1.SecretList
+	err := kubeClient.List(ctx, &secrets, client.InNamespace(helmRelease.GetStorageNamespace()),
+		client.MatchingLabels{"owner": "helm", "name": helmRelease.GetReleaseName()})
+	if err != nil {
+		return nil, err
+	}
+
+	var history []helmReleaseRevision
+	for _, secret := range secrets.Items {
+		rev, err := decodeHelmReleaseRevision(secret.Data["release"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Helm release secret %s: %w", secret.Name, err)
+		}
+		history = append(history, rev)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Version < history[j].Version
+	})
+	return history, nil
+}
+
+// decodeHelmReleaseRevision decodes a release encoded by the Helm storage,
+// which is base64 encoded gzipped JSON.
+func decodeHelmReleaseRevision(data []byte) (helmReleaseRevision, error) {
+	var rev helmReleaseRevision
+	b, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return rev, err
+	}
+	if bytes.HasPrefix(b, []byte{0x1f, 0x8b, 0x08}) {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return rev, err
+		}
+		defer r.Close()
+		if b, err = ioutil.ReadAll(r); err != nil {
+			return rev, err
+		}
+	}
+	err = json.Unmarshal(b, &rev)
+	return rev, err
+}
+
+// rollbackHelmRelease pins the chart version of the revision on the
+// HelmRelease, and requests its reconciliation. The values are left
+// untouched, as the values of the revision include the ones resolved
+// from the values references, which may come from Secrets.
+func rollbackHelmRelease(ctx context.Context, kubeClient client.Client,
+	namespacedName types.NamespacedName, helmRelease *helmv2.HelmRelease, rev helmReleaseRevision) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if err := kubeClient.Get(ctx, namespacedName, helmRelease); err != nil {
+			return err
+		}
+		helmRelease.Spec.Chart.Spec.Version = rev.Chart.Metadata.Version
+		if helmRelease.Annotations == nil {
+			helmRelease.Annotations = map[string]string{}
+		}
+		helmRelease.Annotations[meta.ReconcileRequestAnnotation] = time.Now().Format(time.RFC3339Nano)
+		return kubeClient.Update(ctx, helmRelease)
+	})
+}
@@ -19,9 +19,13 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/fluxcd/flux2/internal/utils"
 )
@@ -32,7 +36,31 @@ var suspendCmd = &cobra.Command{
 	Long:  "The suspend sub-commands suspend the reconciliation of a resource.",
 }
 
+type suspendFlags struct {
+	all    bool
+	reason string
+	user   string
+}
+
+var suspendArgs suspendFlags
+
+// The annotations recording why, by whom and when a resource was suspended,
+// these are removed by the resume commands.
+const (
+	suspendReasonAnnotation = "reconcile.fluxcd.io/suspendReason"
+	suspendedByAnnotation   = "reconcile.fluxcd.io/suspendedBy"
+	suspendedAtAnnotation   = "reconcile.fluxcd.io/suspendedAt"
+)
+
+var suspendAnnotations = []string{suspendReasonAnnotation, suspendedByAnnotation, suspendedAtAnnotation}
+
 func init() {
+	suspendCmd.PersistentFlags().BoolVar(&suspendArgs.all, "all", false,
+		"suspend all the resources of that kind in the namespace")
+	suspendCmd.PersistentFlags().StringVar(&suspendArgs.reason, "reason", "",
+		"the reason for suspending the resources, recorded in the "+suspendReasonAnnotation+" annotation")
+	suspendCmd.PersistentFlags().StringVar(&suspendArgs.user, "user", "",
+		"the user suspending the resources, defaults to the user of the kubeconfig context")
 	rootCmd.AddCommand(suspendCmd)
 }
 
@@ -45,13 +73,13 @@ type suspendable interface {
 type suspendCommand struct {
 	apiType
 	object suspendable
+	list   listAdapter
 }
 
 func (suspend suspendCommand) run(cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
+	if len(args) < 1 && !suspendArgs.all {
 		return fmt.Errorf("%s name is required", suspend.humanKind)
 	}
-	name := args[0]
 
 	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancel()
@@ -61,21 +89,121 @@ func (suspend suspendCommand) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	namespacedName := types.NamespacedName{
-		Namespace: rootArgs.namespace,
-		Name:      name,
+	user := suspendArgs.user
+	if user == "" {
+		if user, err = utils.KubeUser(rootArgs.kubeconfig, rootArgs.kubecontext); err != nil {
+			logger.Warningf("unable to determine the suspending user: %s", err.Error())
+		}
 	}
-	err = kubeClient.Get(ctx, namespacedName, suspend.object.asClientObject())
+
+	names, err := objectNames(ctx, kubeClient, suspend.list.asClientList(), args, suspendArgs.all)
 	if err != nil {
 		return err
 	}
+	if len(names) == 0 {
+		logger.Failuref("no %s objects found in %s namespace", suspend.kind, rootArgs.namespace)
+		return nil
+	}
 
-	logger.Actionf("suspending %s %s in %s namespace", suspend.humanKind, name, rootArgs.namespace)
-	suspend.object.setSuspended()
-	if err := kubeClient.Update(ctx, suspend.object.asClientObject()); err != nil {
-		return err
+	return forEachName(names, "suspended", suspend.humanKind, func(name string) error {
+		namespacedName := types.NamespacedName{
+			Namespace: rootArgs.namespace,
+			Name:      name,
+		}
+		err := kubeClient.Get(ctx, namespacedName, suspend.object.asClientObject())
+		if err != nil {
+			return err
+		}
+
+		logger.Actionf("suspending %s %s in %s namespace", suspend.humanKind, name, rootArgs.namespace)
+		patch := client.MergeFrom(suspend.object.asClientObject().DeepCopyObject().(client.Object))
+		suspend.object.setSuspended()
+		setSuspendAnnotations(suspend.object.asClientObject(), suspendArgs.reason, user)
+		if err := kubeClient.Patch(ctx, suspend.object.asClientObject(), patch); err != nil {
+			return err
+		}
+		logger.Successf("%s suspended", suspend.humanKind)
+		return nil
+	})
+}
+
+// suspendNotSupported returns the run function of the suspend and resume
+// commands for the kinds that have no suspend field in their spec.
+func suspendNotSupported(kind string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("suspend not supported for kind %s", kind)
 	}
-	logger.Successf("%s suspended", suspend.humanKind)
+}
 
+// setSuspendAnnotations records the reason, the user and the time of the
+// suspension on the object.
+func setSuspendAnnotations(obj client.Object, reason, user string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if reason != "" {
+		annotations[suspendReasonAnnotation] = reason
+	} else {
+		delete(annotations, suspendReasonAnnotation)
+	}
+	if user != "" {
+		annotations[suspendedByAnnotation] = user
+	} else {
+		delete(annotations, suspendedByAnnotation)
+	}
+	annotations[suspendedAtAnnotation] = time.Now().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// clearSuspendAnnotations removes the annotations set by
+// setSuspendAnnotations from the object.
+func clearSuspendAnnotations(obj client.Object) {
+	annotations := obj.GetAnnotations()
+	for _, annotation := range suspendAnnotations {
+		delete(annotations, annotation)
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// objectNames returns the names given as arguments, or the names of
+// all the objects of the list kind in the namespace.
+func objectNames(ctx context.Context, kubeClient client.Client, list client.ObjectList, args []string, all bool) ([]string, error) {
+	if !all {
+		return args, nil
+	}
+	if err := kubeClient.List(ctx, list, client.InNamespace(rootArgs.namespace)); err != nil {
+		return nil, err
+	}
+	items, err := apimeta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.(client.Object).GetName())
+	}
+	return names, nil
+}
+
+// forEachName calls fn for each of the names, continuing past the
+// failures. For several names, it reports how many of them succeeded
+// and returns an error listing the ones that failed.
+func forEachName(names []string, done string, kind string, fn func(name string) error) error {
+	if len(names) == 1 {
+		return fn(names[0])
+	}
+
+	var failed []string
+	for _, name := range names {
+		if err := fn(name); err != nil {
+			logger.Failuref("%s %s: %s", kind, name, err.Error())
+			failed = append(failed, name)
+		}
+	}
+	logger.Successf("%d of %d %s objects %s", len(names)-len(failed), len(names), kind, done)
+	if len(failed) > 0 {
+		return fmt.Errorf("%d %s objects failed: %s", len(failed), kind, strings.Join(failed, ", "))
+	}
 	return nil
 }
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
+)
+
+var suspendAlertProviderCmd = &cobra.Command{
+	Use:               "alert-provider [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&notificationv1.ProviderList{}),
+	Short:             "Suspend reconciliation of Provider",
+	Long:              "The suspend alert-provider command is kept for symmetry with the other kinds, it always fails as the Provider API has no suspend field.",
+	Example: `  # Providers can't be suspended, suspend the Alerts using them instead
+  flux suspend alert main
+`,
+	RunE: suspendNotSupported(alertProviderType.kind),
+}
+
+func init() {
+	suspendCmd.AddCommand(suspendAlertProviderCmd)
+}
@@ -23,15 +23,17 @@ import (
 )
 
 var suspendImageRepositoryCmd = &cobra.Command{
-	Use:   "repository [name]",
-	Short: "Suspend reconciliation of an ImageRepository",
-	Long:  "The suspend image repository command disables the reconciliation of a ImageRepository resource.",
+	Use:               "repository [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&imagev1.ImageRepositoryList{}),
+	Short:             "Suspend reconciliation of an ImageRepository",
+	Long:              "The suspend image repository command disables the reconciliation of a ImageRepository resource.",
 	Example: `  # Suspend reconciliation for an existing ImageRepository
   flux suspend image repository alpine
 `,
 	RunE: suspendCommand{
 		apiType: imageRepositoryType,
 		object:  imageRepositoryAdapter{&imagev1.ImageRepository{}},
+		list:    imageRepositoryListAdapter{&imagev1.ImageRepositoryList{}},
 	}.run,
 }
 
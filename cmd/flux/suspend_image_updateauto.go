@@ -23,15 +23,17 @@ import (
 )
 
 var suspendImageUpdateCmd = &cobra.Command{
-	Use:   "update [name]",
-	Short: "Suspend reconciliation of an ImageUpdateAutomation",
-	Long:  "The suspend image update command disables the reconciliation of a ImageUpdateAutomation resource.",
+	Use:               "update [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&autov1.ImageUpdateAutomationList{}),
+	Short:             "Suspend reconciliation of an ImageUpdateAutomation",
+	Long:              "The suspend image update command disables the reconciliation of a ImageUpdateAutomation resource.",
 	Example: `  # Suspend reconciliation for an existing ImageUpdateAutomation
   flux suspend image update latest-images
 `,
 	RunE: suspendCommand{
 		apiType: imageUpdateAutomationType,
 		object:  imageUpdateAutomationAdapter{&autov1.ImageUpdateAutomation{}},
+		list:    imageUpdateAutomationListAdapter{&autov1.ImageUpdateAutomationList{}},
 	}.run,
 }
 
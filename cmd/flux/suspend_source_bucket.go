@@ -22,15 +22,17 @@ import (
 )
 
 var suspendSourceBucketCmd = &cobra.Command{
-	Use:   "bucket [name]",
-	Short: "Suspend reconciliation of a Bucket",
-	Long:  "The suspend command disables the reconciliation of a Bucket resource.",
+	Use:               "bucket [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.BucketList{}),
+	Short:             "Suspend reconciliation of a Bucket",
+	Long:              "The suspend command disables the reconciliation of a Bucket resource.",
 	Example: `  # Suspend reconciliation for an existing Bucket
   flux suspend source bucket podinfo
 `,
 	RunE: suspendCommand{
 		apiType: bucketType,
 		object:  bucketAdapter{&sourcev1.Bucket{}},
+		list:    bucketListAdapter{&sourcev1.BucketList{}},
 	}.run,
 }
 
@@ -23,15 +23,17 @@ import (
 )
 
 var suspendSourceHelmChartCmd = &cobra.Command{
-	Use:   "chart [name]",
-	Short: "Suspend reconciliation of a HelmChart",
-	Long:  "The suspend command disables the reconciliation of a HelmChart resource.",
+	Use:               "chart [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.HelmChartList{}),
+	Short:             "Suspend reconciliation of a HelmChart",
+	Long:              "The suspend command disables the reconciliation of a HelmChart resource.",
 	Example: `  # Suspend reconciliation for an existing HelmChart
   flux suspend source chart podinfo
 `,
 	RunE: suspendCommand{
 		apiType: helmChartType,
 		object:  helmChartAdapter{&sourcev1.HelmChart{}},
+		list:    helmChartListAdapter{&sourcev1.HelmChartList{}},
 	}.run,
 }
 
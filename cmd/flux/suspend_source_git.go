@@ -23,15 +23,17 @@ import (
 )
 
 var suspendSourceGitCmd = &cobra.Command{
-	Use:   "git [name]",
-	Short: "Suspend reconciliation of a GitRepository",
-	Long:  "The suspend command disables the reconciliation of a GitRepository resource.",
+	Use:               "git [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.GitRepositoryList{}),
+	Short:             "Suspend reconciliation of a GitRepository",
+	Long:              "The suspend command disables the reconciliation of a GitRepository resource.",
 	Example: `  # Suspend reconciliation for an existing GitRepository
   flux suspend source git podinfo
 `,
 	RunE: suspendCommand{
 		apiType: gitRepositoryType,
 		object:  gitRepositoryAdapter{&sourcev1.GitRepository{}},
+		list:    gitRepositoryListAdapter{&sourcev1.GitRepositoryList{}},
 	}.run,
 }
 
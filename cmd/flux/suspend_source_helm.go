@@ -23,15 +23,17 @@ import (
 )
 
 var suspendSourceHelmCmd = &cobra.Command{
-	Use:   "helm [name]",
-	Short: "Suspend reconciliation of a HelmRepository",
-	Long:  "The suspend command disables the reconciliation of a HelmRepository resource.",
+	Use:               "helm [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&sourcev1.HelmRepositoryList{}),
+	Short:             "Suspend reconciliation of a HelmRepository",
+	Long:              "The suspend command disables the reconciliation of a HelmRepository resource.",
 	Example: `  # Suspend reconciliation for an existing HelmRepository
   flux suspend source helm bitnami
 `,
 	RunE: suspendCommand{
 		apiType: helmRepositoryType,
 		object:  helmRepositoryAdapter{&sourcev1.HelmRepository{}},
+		list:    helmRepositoryListAdapter{&sourcev1.HelmRepositoryList{}},
 	}.run,
 }
 
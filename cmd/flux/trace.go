@@ -0,0 +1,307 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/flux2/internal/flags"
+	"github.com/fluxcd/flux2/internal/utils"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+)
+
+var traceCmd = &cobra.Command{
+	Use:   "trace [kind] [name]",
+	Short: "Trace an in-cluster object throughout the GitOps delivery pipeline",
+	Long: `The trace command shows how an object is managed by Flux, from the Kustomization or HelmRelease
+that applied it, to the source and the revision it was built from.
+The manager is looked up from the kustomize.toolkit.fluxcd.io and helm.toolkit.fluxcd.io labels of the object.`,
+	Example: `  # Trace a Deployment
+  flux trace deployment podinfo --namespace=apps
+
+  # Trace a cluster scoped object
+  flux trace namespace apps
+
+  # Print the trace in YAML format
+  flux trace deployment podinfo --namespace=apps -o yaml
+`,
+	RunE: traceCmdRun,
+}
+
+type traceFlags struct {
+	output flags.ExportFormat
+}
+
+var traceArgs traceFlags
+
+func init() {
+	traceCmd.Flags().VarP(&traceArgs.output, "output", "o",
+		"print the trace in a machine readable format instead of a tree, available options are: (yaml, json)")
+	rootCmd.AddCommand(traceCmd)
+}
+
+// the labels set by the Flux controllers on the objects they apply
+const (
+	kustomizeNameLabel      = "kustomize.toolkit.fluxcd.io/name"
+	kustomizeNamespaceLabel = "kustomize.toolkit.fluxcd.io/namespace"
+	helmNameLabel           = "helm.toolkit.fluxcd.io/name"
+	helmNamespaceLabel      = "helm.toolkit.fluxcd.io/namespace"
+)
+
+// traceNode is an object in the chain from a traced object to its source.
+type traceNode struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Chart     string `json:"chart,omitempty"`
+	Version   string `json:"version,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Ready     string `json:"ready,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+func traceCmdRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("object kind and name are required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	mapping, err := resolveKind(kubeClient.RESTMapper(), args[0])
+	if err != nil {
+		return err
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(mapping.GroupVersionKind)
+	namespacedName := types.NamespacedName{Name: args[1]}
+	if mapping.Scope.Name() == apimeta.RESTScopeNameNamespace {
+		namespacedName.Namespace = rootArgs.namespace
+	}
+	if err := kubeClient.Get(ctx, namespacedName, object); err != nil {
+		return err
+	}
+
+	chain, err := traceObject(ctx, kubeClient, object)
+	if err != nil {
+		return err
+	}
+
+	if traceArgs.output != "" {
+		return printExport(os.Stdout, chain, traceArgs.output.String())
+	}
+	printTrace(os.Stdout, chain)
+	return nil
+}
+
+// resolveKind maps a kind given as on the kubectl command line, e.g.
+// 'deployment', 'deployments.apps' or 'deployments.v1.apps', to its
+// REST mapping.
+func resolveKind(mapper apimeta.RESTMapper, kind string) (*apimeta.RESTMapping, error) {
+	gvr, gr := schema.ParseResourceArg(strings.ToLower(kind))
+	var gvk schema.GroupVersionKind
+	var err error
+	if gvr != nil {
+		gvk, err = mapper.KindFor(*gvr)
+	}
+	if gvr == nil || err != nil {
+		gvk, err = mapper.KindFor(gr.WithVersion(""))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unknown kind '%s': %w", kind, err)
+	}
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// traceObject returns the chain from the object to the source of its
+// manager. The chain ends early when the object is not managed by Flux
+// or when the manager or the source no longer exist.
+func traceObject(ctx context.Context, kubeClient client.Client, object client.Object) ([]traceNode, error) {
+	chain := []traceNode{{
+		Kind:      object.GetObjectKind().GroupVersionKind().Kind,
+		Name:      object.GetName(),
+		Namespace: object.GetNamespace(),
+	}}
+
+	labels := object.GetLabels()
+	switch {
+	case labels[helmNameLabel] != "":
+		namespacedName := types.NamespacedName{
+			Namespace: labels[helmNamespaceLabel],
+			Name:      labels[helmNameLabel],
+		}
+		var helmRelease helmv2.HelmRelease
+		node, err := getTraceNode(ctx, kubeClient, helmv2.HelmReleaseKind, namespacedName, &helmRelease)
+		if err != nil || node.Message != "" {
+			return append(chain, node), err
+		}
+		node.Chart = helmRelease.Spec.Chart.Spec.Chart
+		node.Version = helmRelease.Spec.Chart.Spec.Version
+		node.Revision = helmRelease.Status.LastAppliedRevision
+		node.Ready, node.Message = statusAndMessage(helmRelease.Status.Conditions)
+		chain = append(chain, node)
+
+		ref := helmRelease.Spec.Chart.Spec.SourceRef
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = helmRelease.Namespace
+		}
+		source, err := traceSource(ctx, kubeClient, ref.Kind, types.NamespacedName{Namespace: namespace, Name: ref.Name})
+		return append(chain, source), err
+	case labels[kustomizeNameLabel] != "":
+		namespacedName := types.NamespacedName{
+			Namespace: labels[kustomizeNamespaceLabel],
+			Name:      labels[kustomizeNameLabel],
+		}
+		var kustomization kustomizev1.Kustomization
+		node, err := getTraceNode(ctx, kubeClient, kustomizev1.KustomizationKind, namespacedName, &kustomization)
+		if err != nil || node.Message != "" {
+			return append(chain, node), err
+		}
+		node.Path = kustomization.Spec.Path
+		node.Revision = kustomization.Status.LastAppliedRevision
+		node.Ready, node.Message = statusAndMessage(kustomization.Status.Conditions)
+		chain = append(chain, node)
+
+		ref := kustomization.Spec.SourceRef
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = kustomization.Namespace
+		}
+		source, err := traceSource(ctx, kubeClient, ref.Kind, types.NamespacedName{Namespace: namespace, Name: ref.Name})
+		return append(chain, source), err
+	default:
+		chain[0].Message = "not managed by Flux"
+		return chain, nil
+	}
+}
+
+// traceSource returns the node of the source with the given kind.
+func traceSource(ctx context.Context, kubeClient client.Client, kind string, namespacedName types.NamespacedName) (traceNode, error) {
+	var object client.Object
+	switch kind {
+	case sourcev1.GitRepositoryKind:
+		object = &sourcev1.GitRepository{}
+	case sourcev1.HelmRepositoryKind:
+		object = &sourcev1.HelmRepository{}
+	case sourcev1.BucketKind:
+		object = &sourcev1.Bucket{}
+	default:
+		return traceNode{}, fmt.Errorf("unsupported source kind '%s'", kind)
+	}
+
+	node, err := getTraceNode(ctx, kubeClient, kind, namespacedName, object)
+	if err != nil || node.Message != "" {
+		return node, err
+	}
+
+	var artifact *sourcev1.Artifact
+	var conditions []metav1.Condition
+	switch source := object.(type) {
+	case *sourcev1.GitRepository:
+		node.URL = source.Spec.URL
+		artifact, conditions = source.GetArtifact(), source.Status.Conditions
+	case *sourcev1.HelmRepository:
+		node.URL = source.Spec.URL
+		artifact, conditions = source.GetArtifact(), source.Status.Conditions
+	case *sourcev1.Bucket:
+		node.URL = fmt.Sprintf("%s/%s", source.Spec.Endpoint, source.Spec.BucketName)
+		artifact, conditions = source.GetArtifact(), source.Status.Conditions
+	}
+	if artifact != nil {
+		node.Revision = artifact.Revision
+	}
+	node.Ready, node.Message = statusAndMessage(conditions)
+	return node, nil
+}
+
+// getTraceNode loads the object into the given value. When the object
+// does not exist, the returned node says so in its message, as the
+// labels pointing to it are stale.
+func getTraceNode(ctx context.Context, kubeClient client.Client, kind string,
+	namespacedName types.NamespacedName, object client.Object) (traceNode, error) {
+	node := traceNode{
+		Kind:      kind,
+		Name:      namespacedName.Name,
+		Namespace: namespacedName.Namespace,
+	}
+	if err := kubeClient.Get(ctx, namespacedName, object); err != nil {
+		if apierrors.IsNotFound(err) {
+			node.Message = "not found, the reference to it is stale"
+			return node, nil
+		}
+		return node, err
+	}
+	return node, nil
+}
+
+// printTrace prints the chain as a tree, each object being indented
+// under the one it manages or provides the source for.
+func printTrace(w io.Writer, chain []traceNode) {
+	for i, node := range chain {
+		var prefix string
+		if i > 0 {
+			prefix = strings.Repeat("    ", i-1) + "└── "
+		}
+		name := node.Name
+		if node.Namespace != "" {
+			name = node.Namespace + "/" + name
+		}
+		var details []string
+		for _, d := range [][2]string{
+			{"path", node.Path},
+			{"chart", node.Chart},
+			{"version", node.Version},
+			{"url", node.URL},
+			{"revision", node.Revision},
+			{"ready", node.Ready},
+			{"message", node.Message},
+		} {
+			if d[1] != "" {
+				details = append(details, fmt.Sprintf("%s: %s", d[0], d[1]))
+			}
+		}
+		line := fmt.Sprintf("%s%s %s", prefix, node.Kind, name)
+		if len(details) > 0 {
+			line = fmt.Sprintf("%s [%s]", line, strings.Join(details, ", "))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
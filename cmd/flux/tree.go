@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/fluxcd/flux2/internal/flags"
+)
+
+var treeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Print the resources reconciled by Flux",
+	Long:  "The tree sub-commands print the objects applied by a Flux resource as a tree.",
+}
+
+type treeFlags struct {
+	output  flags.ExportFormat
+	compact bool
+}
+
+var treeArgs treeFlags
+
+func init() {
+	treeCmd.PersistentFlags().VarP(&treeArgs.output, "output", "o",
+		"print the tree in a machine readable format, available options are: (yaml, json)")
+	treeCmd.PersistentFlags().BoolVar(&treeArgs.compact, "compact", false,
+		"only print the number of objects per kind instead of every object")
+	rootCmd.AddCommand(treeCmd)
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/flux2/internal/utils"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+)
+
+var treeKsCmd = &cobra.Command{
+	Use:               "kustomization [name]",
+	ValidArgsFunction: resourceNamesCompletionFunc(&kustomizev1.KustomizationList{}),
+	Aliases:           []string{"ks"},
+	Short:             "Print the objects applied by a Kustomization",
+	Long: `The tree kustomization command prints the objects applied by a Kustomization,
+grouped by namespace and kind, together with their status.
+The objects are looked up from the kinds recorded in the Kustomization snapshot and
+the kustomize.toolkit.fluxcd.io labels, the Kustomizations it applied are printed with their own objects.`,
+	Example: `  # Print the objects applied by a Kustomization
+  flux tree kustomization flux-system
+
+  # Print the number of objects per kind
+  flux tree kustomization flux-system --compact
+
+  # Print the tree in JSON format
+  flux tree kustomization flux-system -o json
+`,
+	RunE: treeKsCmdRun,
+}
+
+func init() {
+	treeCmd.AddCommand(treeKsCmd)
+}
+
+// treeNode is an object applied by a Kustomization. With --compact, the
+// objects of a kind are summarised in a single node with their count.
+type treeNode struct {
+	Kind      string     `json:"kind"`
+	Name      string     `json:"name,omitempty"`
+	Namespace string     `json:"namespace,omitempty"`
+	Count     int        `json:"count,omitempty"`
+	Status    string     `json:"status,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	Children  []treeNode `json:"children,omitempty"`
+}
+
+func treeKsCmdRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("kustomization name is required")
+	}
+	name := args[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	namespacedName := types.NamespacedName{
+		Namespace: rootArgs.namespace,
+		Name:      name,
+	}
+	var kustomization kustomizev1.Kustomization
+	if err := kubeClient.Get(ctx, namespacedName, &kustomization); err != nil {
+		return err
+	}
+
+	tree, err := kustomizationTree(ctx, kubeClient, &kustomization, map[types.NamespacedName]bool{})
+	if err != nil {
+		return err
+	}
+
+	if treeArgs.output != "" {
+		return printExport(os.Stdout, tree, treeArgs.output.String())
+	}
+	printTree(os.Stdout, tree, "")
+	return nil
+}
+
+// kustomizationTree returns the node of the Kustomization with the objects
+// it applied as children, recursing into the applied Kustomizations.
+// The visited Kustomizations are tracked to not loop on cyclic trees.
+func kustomizationTree(ctx context.Context, kubeClient client.Client, kustomization *kustomizev1.Kustomization,
+	visited map[types.NamespacedName]bool) (treeNode, error) {
+	node := treeNode{
+		Kind:      kustomizev1.KustomizationKind,
+		Name:      kustomization.Name,
+		Namespace: kustomization.Namespace,
+	}
+	node.Status, node.Message = statusAndMessage(kustomization.Status.Conditions)
+
+	namespacedName := types.NamespacedName{Namespace: kustomization.Namespace, Name: kustomization.Name}
+	if visited[namespacedName] || kustomization.Status.Snapshot == nil {
+		return node, nil
+	}
+	visited[namespacedName] = true
+
+	var objects []unstructured.Unstructured
+	for namespace, kinds := range snapshotKinds(kustomization.Status.Snapshot) {
+		for _, gvk := range kinds {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			opts := []client.ListOption{
+				client.MatchingLabels{
+					kustomizeNameLabel:      kustomization.Name,
+					kustomizeNamespaceLabel: kustomization.Namespace,
+				},
+			}
+			if namespace != "" {
+				opts = append(opts, client.InNamespace(namespace))
+			}
+			if err := kubeClient.List(ctx, list, opts...); err != nil {
+				if apimeta.IsNoMatchError(err) {
+					continue
+				}
+				return node, err
+			}
+			objects = append(objects, list.Items...)
+		}
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		a, b := objects[i], objects[j]
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		if a.GetKind() != b.GetKind() {
+			return a.GetKind() < b.GetKind()
+		}
+		return a.GetName() < b.GetName()
+	})
+
+	for i := range objects {
+		object := &objects[i]
+		gvk := object.GroupVersionKind()
+		if gvk.Group == kustomizev1.GroupVersion.Group && gvk.Kind == kustomizev1.KustomizationKind {
+			var child kustomizev1.Kustomization
+			if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(object), &child); err != nil {
+				return node, err
+			}
+			childNode, err := kustomizationTree(ctx, kubeClient, &child, visited)
+			if err != nil {
+				return node, err
+			}
+			node.Children = append(node.Children, childNode)
+			continue
+		}
+
+		if treeArgs.compact {
+			last := len(node.Children) - 1
+			if last >= 0 && node.Children[last].Count > 0 &&
+				node.Children[last].Kind == object.GetKind() && node.Children[last].Namespace == object.GetNamespace() {
+				node.Children[last].Count++
+				continue
+			}
+			node.Children = append(node.Children, treeNode{
+				Kind:      object.GetKind(),
+				Namespace: object.GetNamespace(),
+				Count:     1,
+			})
+			continue
+		}
+
+		child := treeNode{
+			Kind:      object.GetKind(),
+			Name:      object.GetName(),
+			Namespace: object.GetNamespace(),
+		}
+		if result, err := status.Compute(object); err != nil {
+			child.Message = err.Error()
+		} else {
+			child.Status, child.Message = result.Status.String(), result.Message
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+// snapshotKinds returns the kinds recorded in the snapshot by namespace, the
+// cluster scoped kinds being listed under the empty namespace.
+func snapshotKinds(snapshot *kustomizev1.Snapshot) map[string][]schema.GroupVersionKind {
+	kinds := snapshot.NamespacedKinds()
+	if nonNamespaced := snapshot.NonNamespacedKinds(); len(nonNamespaced) > 0 {
+		kinds[""] = nonNamespaced
+	}
+	return kinds
+}
+
+// printTree prints the node and its children, each child being indented
+// under its parent.
+func printTree(w io.Writer, node treeNode, prefix string) {
+	line := node.Kind
+	switch {
+	case node.Count > 0:
+		if node.Namespace != "" {
+			line = fmt.Sprintf("%s %s", line, node.Namespace)
+		}
+		line = fmt.Sprintf("%s (%d)", line, node.Count)
+	case node.Namespace != "":
+		line = fmt.Sprintf("%s %s/%s", line, node.Namespace, node.Name)
+	default:
+		line = fmt.Sprintf("%s %s", line, node.Name)
+	}
+	if node.Status != "" {
+		line = fmt.Sprintf("%s [%s]", line, node.Status)
+	}
+	if node.Message != "" && node.Status != "True" && node.Status != status.CurrentStatus.String() {
+		line = fmt.Sprintf("%s %s", line, node.Message)
+	}
+	fmt.Fprintln(w, line)
+
+	for i, child := range node.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprint(w, prefix+branch)
+		printTree(w, child, prefix+indent)
+	}
+}
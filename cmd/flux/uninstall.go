@@ -45,6 +45,9 @@ var uninstallCmd = &cobra.Command{
 
   # Uninstall Flux but keep the namespace
   flux uninstall --namespace=infra --keep-namespace=true
+
+  # List the objects that would be deleted, without deleting them
+  flux uninstall --dry-run
 `,
 	RunE: uninstallCmdRun,
 }
@@ -87,6 +90,8 @@ func uninstallCmdRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	warnOrphanedObjects(ctx, kubeClient, uninstallArgs.dryRun)
+
 	logger.Actionf("deleting components in %s namespace", rootArgs.namespace)
 	uninstallComponents(ctx, kubeClient, rootArgs.namespace, uninstallArgs.dryRun)
 
@@ -104,6 +109,26 @@ func uninstallCmdRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// warnOrphanedObjects warns that the objects applied by the Kustomizations
+// and HelmReleases are not garbage collected, as the custom resources are
+// deleted with their definitions once the controllers are gone.
+func warnOrphanedObjects(ctx context.Context, kubeClient client.Client, dryRun bool) {
+	verb := "are"
+	if dryRun {
+		verb = "would be"
+	}
+	var kustomizations kustomizev1.KustomizationList
+	if err := kubeClient.List(ctx, &kustomizations, client.InNamespace("")); err == nil && len(kustomizations.Items) > 0 {
+		logger.Warningf("%d Kustomizations %s deleted, the objects they applied are kept in the cluster without being reconciled",
+			len(kustomizations.Items), verb)
+	}
+	var helmReleases helmv2.HelmReleaseList
+	if err := kubeClient.List(ctx, &helmReleases, client.InNamespace("")); err == nil && len(helmReleases.Items) > 0 {
+		logger.Warningf("%d HelmReleases %s deleted, the Helm releases they installed are kept in the cluster without being reconciled",
+			len(helmReleases.Items), verb)
+	}
+}
+
 func uninstallComponents(ctx context.Context, kubeClient client.Client, namespace string, dryRun bool) {
 	opts, dryRunStr := getDeleteOptions(dryRun)
 	selector := client.MatchingLabels{"app.kubernetes.io/instance": namespace}
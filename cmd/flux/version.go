@@ -1,12 +1,163 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluxcd/flux2/internal/flags"
 	"github.com/fluxcd/flux2/internal/utils"
 	"github.com/fluxcd/flux2/pkg/manifestgen/install"
 )
 
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the CLI, controllers and CRDs versions",
+	Long: `The version command prints the version of the CLI, the versions of the controllers
+read from the image tags of their deployments, and the API versions served by the CRDs.
+The components and CRDs that are not found on the cluster are reported as not installed.`,
+	Example: `  # Print the versions before an upgrade
+  flux version
+
+  # Print the versions as JSON
+  flux version --output=json
+`,
+	RunE: versionCmdRun,
+}
+
+type versionFlags struct {
+	output flags.CheckOutputFormat
+}
+
+var versionArgs versionFlags
+
+func init() {
+	versionCmd.Flags().VarP(&versionArgs.output, "output", "o",
+		"the format in which the versions are printed, available options are: (json)")
+	rootCmd.AddCommand(versionCmd)
+}
+
+// notInstalled is the version of the components and CRDs that are not
+// found on the cluster.
+const notInstalled = "not installed"
+
+type versionReport struct {
+	CLI        string              `json:"cli"`
+	Components map[string]string   `json:"components"`
+	CRDs       map[string][]string `json:"crds"`
+}
+
+func versionCmdRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	kubeClient, err := utils.KubeClient(rootArgs.kubeconfig, rootArgs.kubecontext)
+	if err != nil {
+		return err
+	}
+
+	var list appsv1.DeploymentList
+	selector := client.MatchingLabels{"app.kubernetes.io/instance": rootArgs.namespace}
+	if err := kubeClient.List(ctx, &list, client.InNamespace(rootArgs.namespace), selector); err != nil {
+		return fmt.Errorf("listing the components failed: %w", err)
+	}
+	deployments := make(map[string]appsv1.Deployment)
+	for _, d := range list.Items {
+		deployments[d.Name] = d
+	}
+
+	opts := install.MakeDefaultOptions()
+	components := append(opts.Components, opts.ComponentsExtra...)
+	report := versionReport{
+		CLI:        VERSION,
+		Components: make(map[string]string),
+		CRDs:       make(map[string][]string),
+	}
+	for _, name := range components {
+		report.Components[name] = deploymentVersion(deployments, name)
+		for _, crd := range componentCRDs[name] {
+			versions, err := crdVersions(ctx, kubeClient, crd)
+			if err != nil {
+				return err
+			}
+			report.CRDs[crd] = versions
+		}
+	}
+
+	if versionArgs.output == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	rows := [][]string{{"flux", report.CLI}}
+	for _, name := range components {
+		rows = append(rows, []string{name, report.Components[name]})
+	}
+	utils.PrintTable(os.Stdout, []string{"Component", "Version"}, rows)
+	fmt.Println()
+
+	rows = nil
+	for _, name := range components {
+		for _, crd := range componentCRDs[name] {
+			rows = append(rows, []string{crd, strings.Join(report.CRDs[crd], ", ")})
+		}
+	}
+	utils.PrintTable(os.Stdout, []string{"CRD", "Versions"}, rows)
+	return nil
+}
+
+// deploymentVersion returns the image tag of the component deployment,
+// falling back to its version label.
+func deploymentVersion(deployments map[string]appsv1.Deployment, name string) string {
+	d, ok := deployments[name]
+	if !ok {
+		return notInstalled
+	}
+	for _, c := range d.Spec.Template.Spec.Containers {
+		if v := imageTag(c.Image); v != "" {
+			return v
+		}
+	}
+	if v := d.Labels["app.kubernetes.io/version"]; v != "" {
+		return v
+	}
+	return "unknown"
+}
+
+// crdVersions returns the API versions served by the CRD, the storage
+// version being marked as such.
+func crdVersions(ctx context.Context, kubeClient client.Client, name string) ([]string, error) {
+	var crd apiextensionsv1.CustomResourceDefinition
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: name}, &crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return []string{notInstalled}, nil
+		}
+		return nil, err
+	}
+	var versions []string
+	for _, v := range crd.Spec.Versions {
+		switch {
+		case v.Storage:
+			versions = append(versions, v.Name+" (storage)")
+		case v.Served:
+			versions = append(versions, v.Name)
+		}
+	}
+	return versions, nil
+}
+
 func getVersion(input string) (string, error) {
 	if input == "" {
 		return rootArgs.defaults.Version, nil
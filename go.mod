@@ -10,6 +10,7 @@ require (
 	github.com/fluxcd/image-reflector-controller/api v0.7.1
 	github.com/fluxcd/kustomize-controller/api v0.9.3
 	github.com/fluxcd/notification-controller/api v0.10.0
+	github.com/fluxcd/pkg/apis/kustomize v0.0.1
 	github.com/fluxcd/pkg/apis/meta v0.8.0
 	github.com/fluxcd/pkg/git v0.3.0
 	github.com/fluxcd/pkg/runtime v0.8.5
@@ -20,6 +21,7 @@ require (
 	github.com/google/go-containerregistry v0.2.0
 	github.com/manifoldco/promptui v0.7.0
 	github.com/olekukonko/tablewriter v0.0.4
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
 	golang.org/x/crypto v0.0.0-20201002170205-7f63de1d35b0
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	notificationv1 "github.com/fluxcd/notification-controller/api/v1beta1"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var supportedAlertProviderTypes = []string{
+	notificationv1.GenericProvider,
+	notificationv1.SlackProvider,
+	notificationv1.DiscordProvider,
+	notificationv1.MSTeamsProvider,
+	notificationv1.RocketProvider,
+	notificationv1.GoogleChatProvider,
+	notificationv1.GitHubProvider,
+	notificationv1.GitLabProvider,
+	notificationv1.BitbucketProvider,
+	notificationv1.AzureDevOpsProvider,
+}
+
+type AlertProviderType string
+
+func (a *AlertProviderType) String() string {
+	return string(*a)
+}
+
+func (a *AlertProviderType) Set(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("no alert provider type given, must be one of: %s",
+			strings.Join(supportedAlertProviderTypes, ", "))
+	}
+	if !utils.ContainsItemString(supportedAlertProviderTypes, str) {
+		return fmt.Errorf("unsupported alert provider type '%s', must be one of: %s",
+			str, strings.Join(supportedAlertProviderTypes, ", "))
+	}
+	*a = AlertProviderType(str)
+	return nil
+}
+
+func (a *AlertProviderType) Type() string {
+	return "alertProviderType"
+}
+
+func (a *AlertProviderType) Description() string {
+	return fmt.Sprintf("the type of the provider, available options are: (%s)",
+		strings.Join(supportedAlertProviderTypes, ", "))
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+const (
+	// CRDsPolicySkip skips the install of the chart CRDs.
+	CRDsPolicySkip = "none"
+	// CRDsPolicyCreate creates the chart CRDs that are not present,
+	// the default of Helm.
+	CRDsPolicyCreate = "create"
+)
+
+var supportedCRDsPolicies = []string{CRDsPolicySkip, CRDsPolicyCreate}
+
+type CRDsPolicy string
+
+func (p *CRDsPolicy) String() string {
+	return string(*p)
+}
+
+func (p *CRDsPolicy) Set(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("no CRDs policy given, must be one of: %s",
+			strings.Join(supportedCRDsPolicies, ", "))
+	}
+	if !utils.ContainsItemString(supportedCRDsPolicies, str) {
+		return fmt.Errorf("unsupported CRDs policy '%s', must be one of: %s",
+			str, strings.Join(supportedCRDsPolicies, ", "))
+	}
+	*p = CRDsPolicy(str)
+	return nil
+}
+
+func (p *CRDsPolicy) Type() string {
+	return "crdsPolicy"
+}
+
+func (p *CRDsPolicy) Description() string {
+	return fmt.Sprintf("the install policy of the chart CRDs, available options are: (%s)",
+		strings.Join(supportedCRDsPolicies, ", "))
+}
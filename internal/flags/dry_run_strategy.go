@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var supportedDryRunStrategies = []string{"none", "server"}
+
+type DryRunStrategy string
+
+func (d *DryRunStrategy) String() string {
+	return string(*d)
+}
+
+func (d *DryRunStrategy) Set(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("no dry run strategy given, must be one of: %s",
+			strings.Join(supportedDryRunStrategies, ", "))
+	}
+	if !utils.ContainsItemString(supportedDryRunStrategies, str) {
+		return fmt.Errorf("unsupported dry run strategy '%s', must be one of: %s",
+			str, strings.Join(supportedDryRunStrategies, ", "))
+	}
+	*d = DryRunStrategy(str)
+	return nil
+}
+
+func (d *DryRunStrategy) Type() string {
+	return "dryRunStrategy"
+}
+
+func (d *DryRunStrategy) Description() string {
+	return fmt.Sprintf("with 'server', submit the objects to the API server for validation without persisting them, available options are: (%s)",
+		strings.Join(supportedDryRunStrategies, ", "))
+}
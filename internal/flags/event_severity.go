@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var supportedEventSeverities = []string{"info", "error"}
+
+type EventSeverity string
+
+func (s *EventSeverity) String() string {
+	return string(*s)
+}
+
+func (s *EventSeverity) Set(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("no event severity given, must be one of: %s",
+			strings.Join(supportedEventSeverities, ", "))
+	}
+	if !utils.ContainsItemString(supportedEventSeverities, str) {
+		return fmt.Errorf("unsupported event severity '%s', must be one of: %s",
+			str, strings.Join(supportedEventSeverities, ", "))
+	}
+	*s = EventSeverity(str)
+	return nil
+}
+
+func (s *EventSeverity) Type() string {
+	return "eventSeverity"
+}
+
+func (s *EventSeverity) Description() string {
+	return fmt.Sprintf("severity of events to send alerts for, available options are: (%s)",
+		strings.Join(supportedEventSeverities, ", "))
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var supportedExportFormats = []string{"yaml", "json"}
+
+type ExportFormat string
+
+func (f *ExportFormat) String() string {
+	return string(*f)
+}
+
+func (f *ExportFormat) Set(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("no export format given, must be one of: %s",
+			strings.Join(supportedExportFormats, ", "))
+	}
+	if !utils.ContainsItemString(supportedExportFormats, str) {
+		return fmt.Errorf("unsupported export format '%s', must be one of: %s",
+			str, strings.Join(supportedExportFormats, ", "))
+
+	}
+	*f = ExportFormat(str)
+	return nil
+}
+
+func (f *ExportFormat) Type() string {
+	return "exportFormat"
+}
+
+func (f *ExportFormat) Description() string {
+	return fmt.Sprintf("the format in which the resources are exported, available options are: (%s)", strings.Join(supportedExportFormats, ", "))
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var supportedGetOutputFormats = []string{"wide", "json", "yaml", "csv", "tsv"}
+
+type GetOutputFormat string
+
+func (f *GetOutputFormat) String() string {
+	return string(*f)
+}
+
+func (f *GetOutputFormat) Set(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("no output format given, must be one of: %s",
+			strings.Join(supportedGetOutputFormats, ", "))
+	}
+	if !utils.ContainsItemString(supportedGetOutputFormats, str) {
+		return fmt.Errorf("unsupported output format '%s', must be one of: %s",
+			str, strings.Join(supportedGetOutputFormats, ", "))
+
+	}
+	*f = GetOutputFormat(str)
+	return nil
+}
+
+func (f *GetOutputFormat) Type() string {
+	return "outputFormat"
+}
+
+func (f *GetOutputFormat) Description() string {
+	return fmt.Sprintf("the format in which the objects are printed, available options are: (%s)", strings.Join(supportedGetOutputFormats, ", "))
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var supportedGitVerificationModes = []string{"head"}
+
+type GitVerificationMode string
+
+func (v *GitVerificationMode) String() string {
+	return string(*v)
+}
+
+func (v *GitVerificationMode) Set(str string) error {
+	if str == "" {
+		return nil
+	}
+	if !utils.ContainsItemString(supportedGitVerificationModes, str) {
+		return fmt.Errorf("unsupported verification mode '%s', must be one of: %s",
+			str, strings.Join(supportedGitVerificationModes, ", "))
+	}
+	*v = GitVerificationMode(str)
+	return nil
+}
+
+func (v *GitVerificationMode) Type() string {
+	return "gitVerificationMode"
+}
+
+func (v *GitVerificationMode) Description() string {
+	return fmt.Sprintf("the Git object of which the signature is verified, available options are: (%s)", strings.Join(supportedGitVerificationModes, ", "))
+}
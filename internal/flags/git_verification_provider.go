@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+// The GitRepository API only verifies OpenPGP signatures.
+var supportedGitVerificationProviders = []string{"gpg"}
+
+type GitVerificationProvider string
+
+func (v *GitVerificationProvider) String() string {
+	return string(*v)
+}
+
+func (v *GitVerificationProvider) Set(str string) error {
+	if str == "" {
+		return nil
+	}
+	if !utils.ContainsItemString(supportedGitVerificationProviders, str) {
+		return fmt.Errorf("unsupported verification provider '%s', must be one of: %s",
+			str, strings.Join(supportedGitVerificationProviders, ", "))
+	}
+	*v = GitVerificationProvider(str)
+	return nil
+}
+
+func (v *GitVerificationProvider) Type() string {
+	return "gitVerificationProvider"
+}
+
+func (v *GitVerificationProvider) Description() string {
+	return fmt.Sprintf("the provider of the commit signatures to verify, available options are: (%s)", strings.Join(supportedGitVerificationProviders, ", "))
+}
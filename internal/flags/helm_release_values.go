@@ -25,16 +25,29 @@ import (
 
 var supportedHelmReleaseValuesFromKinds = []string{"Secret", "ConfigMap"}
 
+// helmReleaseValuesFromOptional is the suffix marking a values reference
+// as optional.
+const helmReleaseValuesFromOptional = "+optional"
+
 type HelmReleaseValuesFrom struct {
-	Kind string
-	Name string
+	Kind      string
+	Name      string
+	ValuesKey string
+	Optional  bool
 }
 
 func (v *HelmReleaseValuesFrom) String() string {
 	if v.Name == "" {
 		return ""
 	}
-	return fmt.Sprintf("%s/%s", v.Kind, v.Name)
+	str := fmt.Sprintf("%s/%s", v.Kind, v.Name)
+	if v.ValuesKey != "" {
+		str += ":" + v.ValuesKey
+	}
+	if v.Optional {
+		str += helmReleaseValuesFromOptional
+	}
+	return str
 }
 
 func (v *HelmReleaseValuesFrom) Set(str string) error {
@@ -43,8 +56,19 @@ func (v *HelmReleaseValuesFrom) Set(str string) error {
 			v.Description())
 	}
 
+	optional := strings.HasSuffix(str, helmReleaseValuesFromOptional)
+	str = strings.TrimSuffix(str, helmReleaseValuesFromOptional)
+
+	var valuesKey string
+	if i := strings.Index(str, ":"); i >= 0 {
+		str, valuesKey = str[:i], str[i+1:]
+		if valuesKey == "" {
+			return fmt.Errorf("invalid values key in '%s', must not be empty", str)
+		}
+	}
+
 	sourceKind, sourceName := utils.ParseObjectKindName(str)
-	if sourceKind == "" {
+	if sourceKind == "" || sourceName == "" {
 		return fmt.Errorf("invalid Kubernetes object reference '%s', must be in format <kind>/<name>", str)
 	}
 	cleanSourceKind, ok := utils.ContainsEqualFoldItemString(supportedHelmReleaseValuesFromKinds, sourceKind)
@@ -55,6 +79,8 @@ func (v *HelmReleaseValuesFrom) Set(str string) error {
 
 	v.Name = sourceName
 	v.Kind = cleanSourceKind
+	v.ValuesKey = valuesKey
+	v.Optional = optional
 
 	return nil
 }
@@ -65,8 +91,8 @@ func (v *HelmReleaseValuesFrom) Type() string {
 
 func (v *HelmReleaseValuesFrom) Description() string {
 	return fmt.Sprintf(
-		"Kubernetes object reference that contains the values.yaml data key in the format '<kind>/<name>', "+
-			"where kind must be one of: (%s)",
+		"Kubernetes object reference that contains the values.yaml data key in the format '<kind>/<name>[:<key>][+optional]', "+
+			"where kind must be one of: (%s), key overrides the values.yaml data key, and +optional ignores a missing object",
 		strings.Join(supportedHelmReleaseValuesFromKinds, ", "),
 	)
 }
@@ -30,6 +30,10 @@ func TestHelmReleaseValuesFrom_Set(t *testing.T) {
 		{"supported", "Secret/foo", "Secret/foo", false},
 		{"lower case kind", "secret/foo", "Secret/foo", false},
 		{"unsupported", "Unsupported/kind", "", true},
+		{"values key", "ConfigMap/foo:prod.yaml", "ConfigMap/foo:prod.yaml", false},
+		{"optional", "Secret/foo+optional", "Secret/foo+optional", false},
+		{"values key and optional", "secret/foo:prod.yaml+optional", "Secret/foo:prod.yaml+optional", false},
+		{"empty values key", "Secret/foo:", "", true},
 		{"invalid format", "Secret", "", true},
 		{"empty", "", "", true},
 	}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var supportedSortKeys = []string{"name", "namespace", "ready", "last-applied"}
+
+type SortKey string
+
+func (k *SortKey) String() string {
+	return string(*k)
+}
+
+func (k *SortKey) Set(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("no sort key given, must be one of: %s",
+			strings.Join(supportedSortKeys, ", "))
+	}
+	if !utils.ContainsItemString(supportedSortKeys, str) {
+		return fmt.Errorf("unsupported sort key '%s', must be one of: %s",
+			str, strings.Join(supportedSortKeys, ", "))
+	}
+	*k = SortKey(str)
+	return nil
+}
+
+func (k *SortKey) Type() string {
+	return "key"
+}
+
+func (k *SortKey) Description() string {
+	return fmt.Sprintf("sort the listed objects by the given key, available options are: (%s)",
+		strings.Join(supportedSortKeys, ", "))
+}
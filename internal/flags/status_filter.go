@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/flux2/internal/utils"
+)
+
+var supportedStatusFilters = []string{"ready", "not-ready", "reconciling", "failed", "suspended"}
+
+type StatusFilter string
+
+func (f *StatusFilter) String() string {
+	return string(*f)
+}
+
+func (f *StatusFilter) Set(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("no status given, must be one of: %s",
+			strings.Join(supportedStatusFilters, ", "))
+	}
+	if !utils.ContainsItemString(supportedStatusFilters, str) {
+		return fmt.Errorf("unsupported status '%s', must be one of: %s",
+			str, strings.Join(supportedStatusFilters, ", "))
+	}
+	*f = StatusFilter(str)
+	return nil
+}
+
+func (f *StatusFilter) Type() string {
+	return "status"
+}
+
+func (f *StatusFilter) Description() string {
+	return fmt.Sprintf("only list the objects with the given status, available options are: (%s)",
+		strings.Join(supportedStatusFilters, ", "))
+}
@@ -20,14 +20,19 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"text/template"
+	"time"
 
 	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
 	imageautov1 "github.com/fluxcd/image-automation-controller/api/v1alpha1"
@@ -37,15 +42,20 @@ import (
 	"github.com/fluxcd/pkg/runtime/dependency"
 	"github.com/fluxcd/pkg/version"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/olekukonko/tablewriter"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/fluxcd/flux2/pkg/manifestgen/install"
@@ -74,6 +84,10 @@ func ExecKubectlCommand(ctx context.Context, mode ExecMode, kubeConfigPath strin
 	}
 
 	c := exec.CommandContext(ctx, "kubectl", args...)
+	// kubectl merges a list of kubeconfig files only from the environment
+	if len(filepath.SplitList(kubeConfigPath)) > 1 {
+		c.Env = append(os.Environ(), "KUBECONFIG="+kubeConfigPath)
+	}
 
 	if mode == ModeStderrOS {
 		c.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
@@ -135,7 +149,6 @@ func ExecTemplate(obj interface{}, tmpl, filename string) error {
 }
 
 func KubeConfig(kubeConfigPath string, kubeContext string) (*rest.Config, error) {
-	configFiles := SplitKubeConfigPath(kubeConfigPath)
 	configOverrides := clientcmd.ConfigOverrides{}
 
 	if len(kubeContext) > 0 {
@@ -143,7 +156,7 @@ func KubeConfig(kubeConfigPath string, kubeContext string) (*rest.Config, error)
 	}
 
 	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{Precedence: configFiles},
+		kubeConfigLoadingRules(kubeConfigPath),
 		&configOverrides,
 	).ClientConfig()
 
@@ -154,12 +167,81 @@ func KubeConfig(kubeConfigPath string, kubeContext string) (*rest.Config, error)
 	return cfg, nil
 }
 
+// KubeUser returns the name of the user of the given kubeconfig context,
+// or of the current context if none is given.
+func KubeUser(kubeConfigPath string, kubeContext string) (string, error) {
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		kubeConfigLoadingRules(kubeConfigPath),
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("kubernetes configuration load failed: %w", err)
+	}
+
+	if kubeContext == "" {
+		kubeContext = cfg.CurrentContext
+	}
+	ctx, ok := cfg.Contexts[kubeContext]
+	if !ok {
+		return "", fmt.Errorf("context '%s' not found in kubernetes configuration", kubeContext)
+	}
+	return ctx.AuthInfo, nil
+}
+
+// KubeNamespace returns the namespace of the given kubeconfig context, or
+// of the current context if none is given, and an empty string when the
+// context has no namespace.
+func KubeNamespace(kubeConfigPath string, kubeContext string) (string, error) {
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		kubeConfigLoadingRules(kubeConfigPath),
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("kubernetes configuration load failed: %w", err)
+	}
+
+	if kubeContext == "" {
+		kubeContext = cfg.CurrentContext
+	}
+	if ctx, ok := cfg.Contexts[kubeContext]; ok {
+		return ctx.Namespace, nil
+	}
+	return "", nil
+}
+
 func KubeClient(kubeConfigPath string, kubeContext string) (client.Client, error) {
 	cfg, err := KubeConfig(kubeConfigPath, kubeContext)
 	if err != nil {
 		return nil, fmt.Errorf("kubernetes client initialization failed: %w", err)
 	}
 
+	scheme := NewScheme()
+
+	// the client discovers the API on creation, which is retried
+	// while the cluster is unreachable
+	var kubeClient client.Client
+	backoff := wait.Backoff{
+		Duration: 500 * time.Millisecond,
+		Factor:   2,
+		Steps:    KubeConnectRetries + 1,
+		Cap:      10 * time.Second,
+	}
+	err = retry.OnError(backoff, IsConnectionError, func() (err error) {
+		kubeClient, err = client.New(cfg, client.Options{
+			Scheme: scheme,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client initialization failed: %w", err)
+	}
+
+	return kubeClient, nil
+}
+
+// NewScheme returns a scheme with the Kubernetes and Flux APIs used by
+// the CLI.
+func NewScheme() *apiruntime.Scheme {
 	scheme := apiruntime.NewScheme()
 	_ = apiextensionsv1.AddToScheme(scheme)
 	_ = corev1.AddToScheme(scheme)
@@ -172,15 +254,72 @@ func KubeClient(kubeConfigPath string, kubeContext string) (client.Client, error
 	_ = notificationv1.AddToScheme(scheme)
 	_ = imagereflectv1.AddToScheme(scheme)
 	_ = imageautov1.AddToScheme(scheme)
+	return scheme
+}
 
-	kubeClient, err := client.New(cfg, client.Options{
-		Scheme: scheme,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("kubernetes client initialization failed: %w", err)
+// KubeConnectRetries is the number of times KubeClient retries to connect
+// to the cluster.
+var KubeConnectRetries = 3
+
+// IsConnectionError returns true if the error is caused by the cluster
+// being unreachable or unavailable, as opposed to errors like
+// authentication or certificate failures which are not solved by retrying.
+func IsConnectionError(err error) bool {
+	if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) || isCertificateError(err) {
+		return false
 	}
+	if apierrors.IsServiceUnavailable(err) || apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) {
+		return true
+	}
+	if utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
 
-	return kubeClient, nil
+// isCertificateError returns true if the error is caused by the TLS
+// handshake with the cluster, like an untrusted or invalid certificate.
+func isCertificateError(err error) bool {
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var systemRootsErr x509.SystemRootsError
+	var recordHeaderErr tls.RecordHeaderError
+	return errors.As(err, &unknownAuthorityErr) || errors.As(err, &invalidErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &systemRootsErr) ||
+		errors.As(err, &recordHeaderErr)
+}
+
+// kubeConfigLoadingRules returns the kubectl loading rules for the given
+// kubeconfig path. Without a path, the KUBECONFIG files are merged, or
+// ~/.kube/config is loaded. A path listing several files is merged in
+// the same way, while a single file is loaded as an explicit path.
+func kubeConfigLoadingRules(kubeConfigPath string) *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	var files []string
+	for _, file := range SplitKubeConfigPath(kubeConfigPath) {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	switch len(files) {
+	case 0:
+	case 1:
+		rules.ExplicitPath = files[0]
+	default:
+		rules.Precedence = files
+	}
+	return rules
 }
 
 // SplitKubeConfigPath splits the given KUBECONFIG path based on the runtime OS
@@ -226,6 +365,41 @@ func ParseObjectKindName(input string) (string, string) {
 	return kind, name
 }
 
+// ReadNames reads a newline-separated list of object names, skipping
+// blank lines and lines starting with '#'.
+func ReadNames(r io.Reader) ([]string, error) {
+	var names []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// ValidateDependsOn checks that the dependencies are given in the
+// '<name>' or '<namespace>/<name>' format with non-empty parts.
+func ValidateDependsOn(deps []string) error {
+	for _, dep := range deps {
+		parts := strings.Split(dep, "/")
+		if len(parts) > 2 {
+			return fmt.Errorf("invalid dependency '%s', must be in the format '<name>' or '<namespace>/<name>'", dep)
+		}
+		for _, part := range parts {
+			if strings.TrimSpace(part) == "" {
+				return fmt.Errorf("invalid dependency '%s', the name and namespace must not be empty", dep)
+			}
+		}
+	}
+	return nil
+}
+
 func MakeDependsOn(deps []string) []dependency.CrossNamespaceDependencyReference {
 	refs := []dependency.CrossNamespaceDependencyReference{}
 	for _, dep := range deps {
@@ -264,6 +438,27 @@ func PrintTable(writer io.Writer, header []string, rows [][]string) {
 	table.Render()
 }
 
+// ValidateRegistry checks that the registry is a well-formed repository
+// prefix, e.g. ghcr.io/fluxcd or localhost:5000, to which the component
+// names are joined to make the image references.
+func ValidateRegistry(registry string) error {
+	if registry == "" {
+		return fmt.Errorf("registry must not be empty")
+	}
+	if strings.Contains(registry, "://") {
+		return fmt.Errorf("invalid registry '%s', must not contain a scheme", registry)
+	}
+	if strings.HasSuffix(registry, "/") {
+		return fmt.Errorf("invalid registry '%s', must not end with a slash", registry)
+	}
+	// validate the image repository the registry is joined into, as a
+	// bare host:port isn't a valid repository on its own
+	if _, err := name.NewRepository(registry + "/source-controller"); err != nil {
+		return fmt.Errorf("invalid registry '%s': %w", registry, err)
+	}
+	return nil
+}
+
 func ValidateComponents(components []string) error {
 	defaults := install.MakeDefaultOptions()
 	bootstrapAllComponents := append(defaults.Components, defaults.ComponentsExtra...)
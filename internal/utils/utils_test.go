@@ -16,7 +16,23 @@ limitations under the License.
 
 package utils
 
-import "testing"
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
 
 func TestCompatibleVersion(t *testing.T) {
 	tests := []struct {
@@ -40,3 +56,185 @@ func TestCompatibleVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestReadNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"single name", "podinfo\n", []string{"podinfo"}},
+		{"without trailing newline", "podinfo\nflux-system", []string{"podinfo", "flux-system"}},
+		{"blank lines and comments", "# sources\npodinfo\n\n  \n#flux-system\nwebapp\n", []string{"podinfo", "webapp"}},
+		{"surrounding whitespace", "  podinfo \t\n", []string{"podinfo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadNames(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ReadNames() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "namespaces"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"wrapped network error", fmt.Errorf("discovery failed: %w", &net.DNSError{Err: "no such host"}), true},
+		{"timeout", &url.Error{Op: "Get", URL: "https://cluster", Err: &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}}, true},
+		{"service unavailable", apierrors.NewServiceUnavailable("restarting"), true},
+		{"unknown authority", &url.Error{Op: "Get", URL: "https://cluster", Err: x509.UnknownAuthorityError{}}, false},
+		{"invalid certificate", &url.Error{Op: "Get", URL: "https://cluster", Err: x509.CertificateInvalidError{Reason: x509.Expired}}, false},
+		{"hostname mismatch", &url.Error{Op: "Get", URL: "https://cluster", Err: x509.HostnameError{Host: "cluster"}}, false},
+		{"tls record header", &url.Error{Op: "Get", URL: "https://cluster", Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}}, false},
+		{"tls alert", &url.Error{Op: "Get", URL: "https://cluster", Err: &net.OpError{Op: "remote error", Err: fmt.Errorf("tls: bad certificate")}}, false},
+		{"unauthorized", apierrors.NewUnauthorized("invalid token"), false},
+		{"forbidden", apierrors.NewForbidden(gr, "", fmt.Errorf("denied")), false},
+		{"other error", fmt.Errorf("invalid configuration"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsConnectionError(tt.err); got != tt.want {
+				t.Errorf("IsConnectionError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error reporting a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestKubeConfigLoadingRules(t *testing.T) {
+	list := strings.Join([]string{"/a/config", "/b/config"}, string(filepath.ListSeparator))
+	tests := []struct {
+		name           string
+		path           string
+		wantExplicit   string
+		wantPrecedence []string
+	}{
+		{"single file", "/a/config", "/a/config", nil},
+		{"list of files", list, "", []string{"/a/config", "/b/config"}},
+		{"empty entries", list + string(filepath.ListSeparator), "", []string{"/a/config", "/b/config"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := kubeConfigLoadingRules(tt.path)
+			if rules.ExplicitPath != tt.wantExplicit {
+				t.Errorf("kubeConfigLoadingRules() ExplicitPath = %v, want %v", rules.ExplicitPath, tt.wantExplicit)
+			}
+			if tt.wantPrecedence != nil && !reflect.DeepEqual(rules.Precedence, tt.wantPrecedence) {
+				t.Errorf("kubeConfigLoadingRules() Precedence = %v, want %v", rules.Precedence, tt.wantPrecedence)
+			}
+		})
+	}
+}
+
+func TestKubeNamespace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kubeconfig := filepath.Join(dir, "config")
+	data := `apiVersion: v1
+kind: Config
+current-context: dev
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+    namespace: apps
+- name: prod
+  context:
+    cluster: prod
+    user: prod
+`
+	if err := ioutil.WriteFile(kubeconfig, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		context string
+		want    string
+	}{
+		{"current context", "", "apps"},
+		{"context without namespace", "prod", ""},
+		{"unknown context", "staging", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KubeNamespace(kubeconfig, tt.context)
+			if err != nil {
+				t.Fatalf("KubeNamespace() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("KubeNamespace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDependsOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		deps    []string
+		wantErr bool
+	}{
+		{"name", []string{"infra"}, false},
+		{"namespace and name", []string{"flux-system/infra", "apps"}, false},
+		{"empty name", []string{""}, true},
+		{"empty namespace", []string{"/infra"}, true},
+		{"empty name with namespace", []string{"flux-system/"}, true},
+		{"too many parts", []string{"a/b/c"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDependsOn(tt.deps); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDependsOn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRegistry(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry string
+		wantErr  bool
+	}{
+		{"host and path", "ghcr.io/fluxcd", false},
+		{"host with port", "registry.local:5000/mirror/fluxcd", false},
+		{"host with port only", "localhost:5000", false},
+		{"host only", "registry.local", false},
+		{"docker hub", "docker.io/fluxcd", false},
+		{"empty", "", true},
+		{"scheme", "https://ghcr.io/fluxcd", true},
+		{"trailing slash", "ghcr.io/fluxcd/", true},
+		{"tag", "ghcr.io/fluxcd:v1", true},
+		{"uppercase", "ghcr.io/FluxCD", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRegistry(tt.registry); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -48,6 +48,8 @@ type Options struct {
 	RSAKeyBits          int
 	ECDSACurve          elliptic.Curve
 	PrivateKeyPath      string
+	KnownHostsPath      string
+	KnownHosts          []byte
 	Username            string
 	Password            string
 	CAFilePath          string
@@ -64,6 +66,7 @@ func MakeDefaultOptions() Options {
 		Labels:              map[string]string{},
 		PrivateKeyAlgorithm: RSAPrivateKeyAlgorithm,
 		PrivateKeyPath:      "",
+		KnownHostsPath:      "",
 		Username:            "",
 		Password:            "",
 		CAFilePath:          "",
@@ -18,6 +18,8 @@ package sourcesecret
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
@@ -56,8 +58,17 @@ func Generate(options Options) (*manifestgen.Manifest, error) {
 
 	var hostKey []byte
 	if keypair != nil {
-		if hostKey, err = scanHostKey(options.SSHHostname); err != nil {
-			return nil, err
+		switch {
+		case len(options.KnownHosts) > 0:
+			hostKey = options.KnownHosts
+		case options.KnownHostsPath != "":
+			if hostKey, err = ioutil.ReadFile(options.KnownHostsPath); err != nil {
+				return nil, fmt.Errorf("failed to read known hosts file: %w", err)
+			}
+		default:
+			if hostKey, err = ScanHostKey(options.SSHHostname); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -66,8 +77,14 @@ func Generate(options Options) (*manifestgen.Manifest, error) {
 		if caFile, err = ioutil.ReadFile(options.CAFilePath); err != nil {
 			return nil, fmt.Errorf("failed to read CA file: %w", err)
 		}
+		if !x509.NewCertPool().AppendCertsFromPEM(caFile) {
+			return nil, fmt.Errorf("failed to parse CA file '%s': no PEM encoded certificates found", options.CAFilePath)
+		}
 	}
 
+	if (options.CertFilePath == "") != (options.KeyFilePath == "") {
+		return nil, fmt.Errorf("both the cert file and the key file are required for TLS authentication")
+	}
 	var certFile, keyFile []byte
 	if options.CertFilePath != "" && options.KeyFilePath != "" {
 		if certFile, err = ioutil.ReadFile(options.CertFilePath); err != nil {
@@ -76,6 +93,9 @@ func Generate(options Options) (*manifestgen.Manifest, error) {
 		if keyFile, err = ioutil.ReadFile(options.KeyFilePath); err != nil {
 			return nil, fmt.Errorf("failed to read key file: %w", err)
 		}
+		if _, err = tls.X509KeyPair(certFile, keyFile); err != nil {
+			return nil, fmt.Errorf("failed to parse cert and key files: %w", err)
+		}
 	}
 
 	secret := buildSecret(keypair, hostKey, caFile, certFile, keyFile, options)
@@ -166,7 +186,9 @@ func generateKeyPair(options Options) (*ssh.KeyPair, error) {
 	return pair, nil
 }
 
-func scanHostKey(host string) ([]byte, error) {
+// ScanHostKey returns the host keys of the SSH server in the known_hosts
+// format. The default SSH port is used when the host has no port.
+func ScanHostKey(host string) ([]byte, error) {
 	if _, _, err := net.SplitHostPort(host); err != nil {
 		// Assume we are dealing with a hostname without a port,
 		// append the default SSH port as this is required for